@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// BuildSelectorIndex parses an ABI once and maps each method's 4-byte
+// selector to the method, so many transactions can be decoded without
+// re-parsing the ABI for each one
+func BuildSelectorIndex(abiJSON string) (map[[4]byte]abi.Method, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+
+	index := make(map[[4]byte]abi.Method, len(parsed.Methods))
+	for _, method := range parsed.Methods {
+		var selector [4]byte
+		copy(selector[:], method.ID)
+		index[selector] = method
+	}
+	return index, nil
+}
+
+// DecodeTxInput decodes transaction call data using a selector index built
+// by BuildSelectorIndex, returning the method name and its named arguments
+func DecodeTxInput(index map[[4]byte]abi.Method, input []byte) (string, map[string]interface{}, error) {
+	if len(input) < 4 {
+		return "", nil, fmt.Errorf("input too short for a method selector")
+	}
+	var selector [4]byte
+	copy(selector[:], input[:4])
+	method, ok := index[selector]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown method selector %x", selector)
+	}
+
+	args := make(map[string]interface{})
+	if err := method.Inputs.UnpackIntoMap(args, input[4:]); err != nil {
+		return "", nil, fmt.Errorf("failed to decode %s arguments: %w", method.Name, err)
+	}
+	return method.Name, args, nil
+}
+
+// DecodeConstructorArgs decodes the constructor arguments of a contract
+// creation transaction. The transaction data must start with bytecode, the
+// contract's hex creation code as emitted by the compiler; the remainder is
+// unpacked against the ABI's constructor.
+func DecodeConstructorArgs(deployTx *types.Transaction, abiJSON, bytecode string) (map[string]interface{}, error) {
+	if deployTx.To() != nil {
+		return nil, fmt.Errorf("transaction %s is not a contract creation", deployTx.Hash().Hex())
+	}
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	code := common.FromHex(bytecode)
+	if len(code) == 0 || !bytes.HasPrefix(deployTx.Data(), code) {
+		return nil, fmt.Errorf("deployment data does not start with the given bytecode")
+	}
+
+	args := make(map[string]interface{})
+	if err := parsed.Constructor.Inputs.UnpackIntoMap(args, deployTx.Data()[len(code):]); err != nil {
+		return nil, fmt.Errorf("failed to decode constructor arguments: %w", err)
+	}
+	return args, nil
+}
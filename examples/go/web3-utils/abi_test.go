@@ -0,0 +1,90 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const erc20ABI = `[
+	{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"approve","stateMutability":"nonpayable","inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]}
+]`
+
+func TestBuildSelectorIndex(t *testing.T) {
+	index, err := BuildSelectorIndex(erc20ABI)
+	if err != nil {
+		t.Fatalf("BuildSelectorIndex: %v", err)
+	}
+	if len(index) != 3 {
+		t.Fatalf("index has %d methods, want 3", len(index))
+	}
+	method, ok := index[[4]byte{0xa9, 0x05, 0x9c, 0xbb}]
+	if !ok || method.Name != "transfer" {
+		t.Fatalf("transfer selector not indexed: %+v", method)
+	}
+}
+
+func TestDecodeTxInput(t *testing.T) {
+	index, err := BuildSelectorIndex(erc20ABI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := hexutil.MustDecode("0xa9059cbb" +
+		"000000000000000000000000d8da6bf26964af9d7eed9e03e53415d37aa96045" +
+		"00000000000000000000000000000000000000000000000000000000000f4240")
+
+	name, args, err := DecodeTxInput(index, input)
+	if err != nil {
+		t.Fatalf("DecodeTxInput: %v", err)
+	}
+	if name != "transfer" {
+		t.Fatalf("method = %s, want transfer", name)
+	}
+	if to := args["to"].(common.Address); to != common.HexToAddress(testAddress) {
+		t.Fatalf("to = %s", to.Hex())
+	}
+	if amount := args["amount"].(*big.Int); amount.Int64() != 1000000 {
+		t.Fatalf("amount = %s, want 1000000", amount)
+	}
+
+	if _, _, err := DecodeTxInput(index, hexutil.MustDecode("0xdeadbeef")); err == nil {
+		t.Fatal("expected error for unknown selector")
+	}
+}
+
+func TestDecodeConstructorArgs(t *testing.T) {
+	const tokenABI = `[{"type":"constructor","stateMutability":"nonpayable","inputs":[
+		{"name":"owner","type":"address"},{"name":"supply","type":"uint256"},{"name":"name","type":"string"}]}]`
+	const bytecode = "0x608060405234801561001057600080fd5b50"
+	data := hexutil.MustDecode(bytecode +
+		"000000000000000000000000d8da6bf26964af9d7eed9e03e53415d37aa96045" +
+		"00000000000000000000000000000000000000000000000000000000000f4240" +
+		"0000000000000000000000000000000000000000000000000000000000000060" +
+		"0000000000000000000000000000000000000000000000000000000000000004" +
+		"5465737400000000000000000000000000000000000000000000000000000000")
+	deployTx := types.NewTx(&types.LegacyTx{Gas: 1_000_000, GasPrice: gwei(10), Data: data})
+
+	args, err := DecodeConstructorArgs(deployTx, tokenABI, bytecode)
+	if err != nil {
+		t.Fatalf("DecodeConstructorArgs: %v", err)
+	}
+	if owner := args["owner"].(common.Address); owner != common.HexToAddress(testAddress) {
+		t.Fatalf("owner = %s", owner.Hex())
+	}
+	if supply := args["supply"].(*big.Int); supply.Int64() != 1000000 {
+		t.Fatalf("supply = %s, want 1000000", supply)
+	}
+	if name := args["name"].(string); name != "Test" {
+		t.Fatalf("name = %q, want Test", name)
+	}
+
+	if _, err := DecodeConstructorArgs(deployTx, tokenABI, "0x6080604052"+"ff"); err == nil {
+		t.Fatal("expected error for mismatched bytecode")
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultLogChunkSize is the largest block range requested in a single
+// eth_getLogs call by default; many providers reject wider ranges
+const DefaultLogChunkSize = 2000
+
+// filterLogsChunked runs q over [fromBlock, toBlock] in chunks of the
+// configured log chunk size
+func (w *Web3Utils) filterLogsChunked(ctx context.Context, q ethereum.FilterQuery, fromBlock, toBlock uint64) ([]types.Log, error) {
+	size := w.logChunkSize
+	var logs []types.Log
+	for start := fromBlock; start <= toBlock; start += size {
+		end := start + size - 1
+		if end > toBlock || end < start {
+			end = toBlock
+		}
+		q.FromBlock = new(big.Int).SetUint64(start)
+		q.ToBlock = new(big.Int).SetUint64(end)
+
+		var chunk []types.Log
+		err := w.call(ctx, func(c *ethclient.Client) (err error) {
+			chunk, err = c.FilterLogs(ctx, q)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get logs for blocks %d-%d: %w", start, end, err)
+		}
+		logs = append(logs, chunk...)
+		if end == toBlock {
+			break
+		}
+	}
+	return logs, nil
+}
+
+// blockBatchSize is how many full blocks AccountTransactions fetches in one
+// batch request
+const blockBatchSize = 100
+
+// AccountTransactions returns the hashes of transactions in blocks fromBlock
+// to toBlock that involve address, ordered by block and position. A
+// transaction matches if address sent it, received it, or appears as an
+// indexed topic of one of its logs (e.g. as an ERC-20 Transfer party). If
+// contracts are given, only logs they emitted are searched, which providers
+// answer far more cheaply than a topic-only query across every contract.
+//
+// The range is scanned in chunks of the configured log chunk size, fetching
+// blocks in batches of blockBatchSize. This is best effort: without an
+// indexer every block in the range is fetched, so it is only practical over
+// small ranges, and internal calls that emit no logs are not found.
+func (w *Web3Utils) AccountTransactions(ctx context.Context, address string, fromBlock, toBlock uint64, contracts ...string) ([]common.Hash, error) {
+	if fromBlock > toBlock {
+		return nil, fmt.Errorf("invalid block range %d-%d", fromBlock, toBlock)
+	}
+	account := common.HexToAddress(address)
+	var emitters []common.Address
+	for _, contract := range contracts {
+		emitters = append(emitters, common.HexToAddress(contract))
+	}
+
+	type position struct {
+		block uint64
+		index uint
+	}
+	found := make(map[common.Hash]position)
+
+	size := w.logChunkSize
+	topic := common.BytesToHash(account.Bytes())
+	for start := fromBlock; start <= toBlock; start += size {
+		end := start + size - 1
+		if end > toBlock || end < start {
+			end = toBlock
+		}
+
+		blocks, err := w.blockTransactions(ctx, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for n, txs := range blocks {
+			for i, tx := range txs {
+				involved := tx.To() != nil && *tx.To() == account
+				if !involved {
+					if sender, err := txSender(tx); err == nil && sender == account {
+						involved = true
+					}
+				}
+				if involved {
+					found[tx.Hash()] = position{start + uint64(n), uint(i)}
+				}
+			}
+		}
+
+		// Look for the address in each indexed topic position
+		for pos := 1; pos <= 3; pos++ {
+			q := ethereum.FilterQuery{Addresses: emitters, Topics: make([][]common.Hash, pos+1)}
+			q.Topics[pos] = []common.Hash{topic}
+			logs, err := w.filterLogsChunked(ctx, q, start, end)
+			if err != nil {
+				return nil, err
+			}
+			for _, l := range logs {
+				if _, ok := found[l.TxHash]; !ok {
+					found[l.TxHash] = position{l.BlockNumber, l.TxIndex}
+				}
+			}
+		}
+		if end == toBlock {
+			break
+		}
+	}
+
+	hashes := make([]common.Hash, 0, len(found))
+	for hash := range found {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		a, b := found[hashes[i]], found[hashes[j]]
+		if a.block != b.block {
+			return a.block < b.block
+		}
+		return a.index < b.index
+	})
+	return hashes, nil
+}
+
+// blockTransactions fetches the transactions of blocks fromBlock to toBlock,
+// in batch requests of blockBatchSize blocks, indexed from fromBlock
+func (w *Web3Utils) blockTransactions(ctx context.Context, fromBlock, toBlock uint64) ([][]*types.Transaction, error) {
+	type rpcBlock struct {
+		Transactions []*types.Transaction `json:"transactions"`
+	}
+	txs := make([][]*types.Transaction, 0, toBlock-fromBlock+1)
+	for start := fromBlock; start <= toBlock; start += blockBatchSize {
+		end := start + blockBatchSize - 1
+		if end > toBlock || end < start {
+			end = toBlock
+		}
+		blocks := make([]*rpcBlock, end-start+1)
+		batch := make([]rpc.BatchElem, len(blocks))
+		for i := range batch {
+			batch[i] = rpc.BatchElem{
+				Method: "eth_getBlockByNumber",
+				Args:   []interface{}{hexutil.EncodeUint64(start + uint64(i)), true},
+				Result: &blocks[i],
+			}
+		}
+		err := w.call(ctx, func(c *ethclient.Client) error {
+			return c.Client().BatchCallContext(ctx, batch)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blocks %d-%d: %w", start, end, err)
+		}
+		for i, elem := range batch {
+			num := start + uint64(i)
+			if elem.Error != nil {
+				return nil, fmt.Errorf("failed to get block %d: %w", num, elem.Error)
+			}
+			if blocks[i] == nil {
+				return nil, fmt.Errorf("failed to get block %d: %w", num, ethereum.NotFound)
+			}
+			txs = append(txs, blocks[i].Transactions)
+		}
+		if end == toBlock {
+			break
+		}
+	}
+	return txs, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestAccountTransactions(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := PrivateKeyToAddress(key)
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	unrelated := legacyTx(t, 0, other, big.NewInt(1), gwei(10))
+	incoming := legacyTx(t, 0, account, big.NewInt(2), gwei(10))
+	outgoing, err := types.SignNewTx(key, types.HomesteadSigner{}, &types.LegacyTx{
+		GasPrice: gwei(10), Gas: 21000, To: &other, Value: big.NewInt(3),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenTransfer := common.HexToHash("0xabcdef")
+
+	blocks := map[uint64][]*types.Transaction{
+		100: {unrelated},
+		101: {outgoing},
+		102: {unrelated, incoming},
+	}
+
+	m := newMockRPC(t)
+	m.handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		n, _ := blockTag(params[0])
+		return mockBlock(&types.Header{Number: new(big.Int).SetUint64(n)}, blocks[n]...), nil
+	})
+	m.handle("eth_getLogs", func(params []json.RawMessage) (interface{}, error) {
+		var filter struct {
+			Topics []interface{} `json:"topics"`
+		}
+		if err := json.Unmarshal(params[0], &filter); err != nil {
+			return nil, err
+		}
+		// An ERC-20 Transfer to the account in block 100
+		if len(filter.Topics) != 3 {
+			return []types.Log{}, nil
+		}
+		return []types.Log{{
+			Address:     other,
+			Topics:      []common.Hash{EventTopic("Transfer(address,address,uint256)"), {}, common.BytesToHash(account.Bytes())},
+			BlockNumber: 100,
+			TxHash:      tokenTransfer,
+			TxIndex:     1,
+		}}, nil
+	})
+
+	hashes, err := m.dial(t).AccountTransactions(context.Background(), account.Hex(), 100, 102)
+	if err != nil {
+		t.Fatalf("AccountTransactions: %v", err)
+	}
+	want := []common.Hash{tokenTransfer, outgoing.Hash(), incoming.Hash()}
+	if len(hashes) != len(want) {
+		t.Fatalf("got %d transactions, want %d: %v", len(hashes), len(want), hashes)
+	}
+	for i := range want {
+		if hashes[i] != want[i] {
+			t.Errorf("hashes[%d] = %s, want %s", i, hashes[i].Hex(), want[i].Hex())
+		}
+	}
+	if n := m.callCount("eth_getBlockByNumber"); n != 3 {
+		t.Fatalf("eth_getBlockByNumber called %d times, want 3", n)
+	}
+}
+
+func TestAccountTransactionsChunked(t *testing.T) {
+	account := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	token := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	m := newMockRPC(t)
+	m.handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		n, _ := blockTag(params[0])
+		return mockBlock(&types.Header{Number: new(big.Int).SetUint64(n)}), nil
+	})
+	m.result("eth_getLogs", []types.Log{})
+
+	w := m.dial(t, WithLogChunkSize(2))
+	if _, err := w.AccountTransactions(context.Background(), account.Hex(), 100, 102, token.Hex()); err != nil {
+		t.Fatalf("AccountTransactions: %v", err)
+	}
+
+	// Blocks are fetched in one batch per chunk
+	if got := m.batchSizes(); len(got) != 2 || got[0] != 2 || got[1] != 1 {
+		t.Fatalf("batch sizes = %v, want [2 1]", got)
+	}
+	calls := m.callParams("eth_getLogs")
+	if len(calls) != 6 {
+		t.Fatalf("eth_getLogs called %d times, want 6", len(calls))
+	}
+	for i, params := range calls {
+		var filter struct {
+			FromBlock string           `json:"fromBlock"`
+			ToBlock   string           `json:"toBlock"`
+			Address   []common.Address `json:"address"`
+		}
+		if err := json.Unmarshal(params[0], &filter); err != nil {
+			t.Fatal(err)
+		}
+		want := [2]string{"0x64", "0x65"}
+		if i >= 3 {
+			want = [2]string{"0x66", "0x66"}
+		}
+		if filter.FromBlock != want[0] || filter.ToBlock != want[1] {
+			t.Errorf("query %d spans %s-%s, want %s-%s", i, filter.FromBlock, filter.ToBlock, want[0], want[1])
+		}
+		if len(filter.Address) != 1 || filter.Address[0] != token {
+			t.Errorf("query %d address filter = %v, want [%s]", i, filter.Address, token.Hex())
+		}
+	}
+}
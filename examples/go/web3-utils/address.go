@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ValidateAddress parses a user-supplied address strictly: it must be 0x
+// followed by 40 hex digits, and if the digits mix upper and lower case the
+// EIP-55 checksum must match. All-lowercase and all-uppercase addresses carry
+// no checksum and are accepted.
+func ValidateAddress(s string) (common.Address, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return common.Address{}, fmt.Errorf("address %q must start with 0x", s)
+	}
+	if len(s) != 2+2*common.AddressLength {
+		return common.Address{}, fmt.Errorf("address %q has %d characters, want %d", s, len(s), 2+2*common.AddressLength)
+	}
+	raw, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return common.Address{}, fmt.Errorf("address %q is not valid hex", s)
+	}
+
+	addr := common.BytesToAddress(raw)
+	digits := s[2:]
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) && s != addr.Hex() {
+		return common.Address{}, fmt.Errorf("address %q has an invalid EIP-55 checksum, want %s", s, addr.Hex())
+	}
+	return addr, nil
+}
+
+// ToChecksumAddress renders an address in its EIP-55 mixed-case form
+func ToChecksumAddress(addr common.Address) string {
+	return addr.Hex()
+}
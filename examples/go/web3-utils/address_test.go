@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestValidateAddress(t *testing.T) {
+	// Checksummed address from the EIP-55 specification
+	const checksummed = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	tests := []struct {
+		name  string
+		in    string
+		valid bool
+	}{
+		{"lowercase", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", true},
+		{"uppercase", "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", true},
+		{"checksum", checksummed, true},
+		{"wrong checksum", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", false},
+		{"no prefix", "5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed00", false},
+		{"short", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", false},
+		{"not hex", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeg", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := ValidateAddress(tt.in)
+			if (err == nil) != tt.valid {
+				t.Fatalf("ValidateAddress(%q) err = %v, want valid %v", tt.in, err, tt.valid)
+			}
+			if tt.valid && ToChecksumAddress(addr) != checksummed {
+				t.Fatalf("checksum form = %s, want %s", ToChecksumAddress(addr), checksummed)
+			}
+		})
+	}
+}
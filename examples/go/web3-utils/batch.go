@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// batchTransport is an http.RoundTripper that collects the JSON-RPC requests
+// issued within a short window and sends them to the node as a single batch
+// request, then hands each caller its own response
+type batchTransport struct {
+	base   http.RoundTripper
+	window time.Duration
+
+	mu      sync.Mutex
+	pending []*batchedRequest
+}
+
+type batchedRequest struct {
+	req  *http.Request
+	id   string
+	body []byte
+	done chan batchedResult
+}
+
+type batchedResult struct {
+	resp *http.Response
+	err  error
+}
+
+func newBatchTransport(window time.Duration) *batchTransport {
+	return &batchTransport{base: http.DefaultTransport, window: window}
+}
+
+// RoundTrip queues a single JSON-RPC request for the next batch. Requests
+// that are already batches are forwarded untouched.
+func (t *batchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil {
+		return t.base.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var msg struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if len(body) == 0 || body[0] == '[' || json.Unmarshal(body, &msg) != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		return t.base.RoundTrip(req)
+	}
+
+	br := &batchedRequest{req: req, id: string(msg.ID), body: body, done: make(chan batchedResult, 1)}
+	t.mu.Lock()
+	t.pending = append(t.pending, br)
+	if len(t.pending) == 1 {
+		time.AfterFunc(t.window, t.flush)
+	}
+	t.mu.Unlock()
+
+	select {
+	case res := <-br.done:
+		return res.resp, res.err
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+}
+
+// flush sends every queued request in one batch and distributes the results
+func (t *batchTransport) flush() {
+	t.mu.Lock()
+	queued := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+
+	if len(queued) == 1 {
+		br := queued[0]
+		req := br.req.Clone(br.req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(br.body))
+		req.ContentLength = int64(len(br.body))
+		resp, err := t.base.RoundTrip(req)
+		br.done <- batchedResult{resp, err}
+		return
+	}
+
+	bodies := make([][]byte, len(queued))
+	for i, br := range queued {
+		bodies[i] = br.body
+	}
+	payload := append(append([]byte{'['}, bytes.Join(bodies, []byte{','})...), ']')
+
+	// The batch outlives any single caller, so it must not inherit the
+	// cancellation of the request that happened to be queued first.
+	req := queued[0].req.Clone(queued[0].req.Context())
+	req = req.WithContext(context.WithoutCancel(req.Context()))
+	req.Body = io.NopCloser(bytes.NewReader(payload))
+	req.ContentLength = int64(len(payload))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		for _, br := range queued {
+			br.done <- batchedResult{err: err}
+		}
+		return
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		for _, br := range queued {
+			br.done <- batchedResult{err: err}
+		}
+		return
+	}
+
+	var elems []json.RawMessage
+	if resp.StatusCode != http.StatusOK || json.Unmarshal(data, &elems) != nil {
+		// Not a batch response (e.g. an HTTP error): give everyone a copy.
+		for _, br := range queued {
+			br.done <- batchedResult{resp: cloneResponse(resp, data)}
+		}
+		return
+	}
+
+	byID := make(map[string]json.RawMessage, len(elems))
+	for _, elem := range elems {
+		var msg struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if json.Unmarshal(elem, &msg) == nil {
+			byID[string(msg.ID)] = elem
+		}
+	}
+	for _, br := range queued {
+		elem, ok := byID[br.id]
+		if !ok {
+			br.done <- batchedResult{err: fmt.Errorf("batch response missing id %s", br.id)}
+			continue
+		}
+		br.done <- batchedResult{resp: cloneResponse(resp, elem)}
+	}
+}
+
+// cloneResponse copies resp's status and headers with a new body
+func cloneResponse(resp *http.Response, body []byte) *http.Response {
+	return &http.Response{
+		Status:        resp.Status,
+		StatusCode:    resp.StatusCode,
+		Proto:         resp.Proto,
+		ProtoMajor:    resp.ProtoMajor,
+		ProtoMinor:    resp.ProtoMinor,
+		Header:        resp.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       resp.Request,
+	}
+}
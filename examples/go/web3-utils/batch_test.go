@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatchingCoalescesConcurrentCalls(t *testing.T) {
+	m := newMockRPC(t)
+	m.handle("eth_getBalance", func(params []json.RawMessage) (interface{}, error) {
+		return "0x64", nil
+	})
+
+	w := m.dial(t, WithBatching(50*time.Millisecond))
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			balance, err := w.GetBalance(context.Background(), testAddress)
+			if err == nil && balance.Int64() != 100 {
+				t.Errorf("balance = %s, want 100", balance)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("GetBalance: %v", err)
+		}
+	}
+
+	if sizes := m.batchSizes(); len(sizes) != 1 || sizes[0] != 3 {
+		t.Fatalf("batch sizes = %v, want one batch of 3", sizes)
+	}
+	if n := m.callCount("eth_getBalance"); n != 3 {
+		t.Fatalf("eth_getBalance called %d times, want 3", n)
+	}
+}
+
+func TestBatchingSingleCallNotBatched(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_blockNumber", "0x10")
+
+	n, err := m.dial(t, WithBatching(5*time.Millisecond)).GetBlockNumber(context.Background())
+	if err != nil {
+		t.Fatalf("GetBlockNumber: %v", err)
+	}
+	if n != 16 {
+		t.Fatalf("block = %d, want 16", n)
+	}
+	if sizes := m.batchSizes(); len(sizes) != 0 {
+		t.Fatalf("batch sizes = %v, want no batches", sizes)
+	}
+}
+
+func TestBatchingPreservesPerCallErrors(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_blockNumber", "0x10")
+
+	w := m.dial(t, WithBatching(50*time.Millisecond))
+	var wg sync.WaitGroup
+	var blockErr, priceErr error
+	wg.Add(2)
+	go func() { defer wg.Done(); _, blockErr = w.GetBlockNumber(context.Background()) }()
+	go func() { defer wg.Done(); _, priceErr = w.GetGasPrice(context.Background()) }()
+	wg.Wait()
+
+	if blockErr != nil {
+		t.Fatalf("GetBlockNumber: %v", blockErr)
+	}
+	if priceErr == nil {
+		t.Fatal("expected eth_gasPrice to fail on the mock")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// BeaconConfig holds the beacon chain timing parameters of a network
+type BeaconConfig struct {
+	GenesisTime   time.Time
+	SlotDuration  time.Duration
+	SlotsPerEpoch uint64
+}
+
+// Beacon chain parameters of well-known networks
+var (
+	MainnetBeaconConfig = BeaconConfig{GenesisTime: time.Unix(1606824023, 0), SlotDuration: 12 * time.Second, SlotsPerEpoch: 32}
+	SepoliaBeaconConfig = BeaconConfig{GenesisTime: time.Unix(1655733600, 0), SlotDuration: 12 * time.Second, SlotsPerEpoch: 32}
+	HoleskyBeaconConfig = BeaconConfig{GenesisTime: time.Unix(1695902400, 0), SlotDuration: 12 * time.Second, SlotsPerEpoch: 32}
+)
+
+// ParentBeaconRoot returns the parent beacon block root (EIP-4788) recorded in
+// the header of the given block, or the latest block if number is nil.
+// Blocks from before the Dencun upgrade carry no root and yield an empty hash.
+func (w *Web3Utils) ParentBeaconRoot(ctx context.Context, number *big.Int) (common.Hash, error) {
+	var header *types.Header
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		header, err = c.HeaderByNumber(ctx, number)
+		return err
+	})
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get header: %w", err)
+	}
+	if header.ParentBeaconRoot == nil {
+		return common.Hash{}, nil
+	}
+	return *header.ParentBeaconRoot, nil
+}
+
+// SlotAndEpoch returns the beacon chain slot and epoch of the latest block,
+// derived from its timestamp and the configured BeaconConfig (mainnet by
+// default; see WithBeaconConfig)
+func (w *Web3Utils) SlotAndEpoch(ctx context.Context) (slot, epoch uint64, err error) {
+	cfg := w.beacon
+	if cfg.SlotDuration <= 0 || cfg.SlotsPerEpoch == 0 {
+		return 0, 0, fmt.Errorf("invalid beacon config")
+	}
+	header, err := w.latestHeader(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	blockTime := time.Unix(int64(header.Time), 0)
+	if blockTime.Before(cfg.GenesisTime) {
+		return 0, 0, fmt.Errorf("block time %v is before beacon genesis %v", blockTime, cfg.GenesisTime)
+	}
+	slot = uint64(blockTime.Sub(cfg.GenesisTime) / cfg.SlotDuration)
+	return slot, slot / cfg.SlotsPerEpoch, nil
+}
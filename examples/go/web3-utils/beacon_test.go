@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestParentBeaconRoot(t *testing.T) {
+	root := common.HexToHash("0xd1c3b7e8a2f64a0e6c3b5d8f9e1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c")
+	zero := uint64(0)
+
+	m := newMockRPC(t)
+	m.result("eth_getBlockByNumber", mockBlock(&types.Header{
+		Number:           big.NewInt(19426587),
+		BaseFee:          gwei(20),
+		WithdrawalsHash:  &types.EmptyWithdrawalsHash,
+		BlobGasUsed:      &zero,
+		ExcessBlobGas:    &zero,
+		ParentBeaconRoot: &root,
+	}))
+
+	got, err := m.dial(t).ParentBeaconRoot(context.Background(), big.NewInt(19426587))
+	if err != nil {
+		t.Fatalf("ParentBeaconRoot: %v", err)
+	}
+	if got != root {
+		t.Fatalf("root = %s, want %s", got.Hex(), root.Hex())
+	}
+
+	legacy := newMockRPC(t)
+	legacy.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(17000000), BaseFee: gwei(20)}))
+	got, err = legacy.dial(t).ParentBeaconRoot(context.Background(), big.NewInt(17000000))
+	if err != nil {
+		t.Fatalf("ParentBeaconRoot pre-Dencun: %v", err)
+	}
+	if got != (common.Hash{}) {
+		t.Fatalf("pre-Dencun root = %s, want empty", got.Hex())
+	}
+}
+
+func TestSlotAndEpoch(t *testing.T) {
+	// Slot 7,000,000 started at genesis + 84,000,000s
+	m := newMockRPC(t)
+	m.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(18000000), Time: 1606824023 + 84000000 + 5}))
+
+	slot, epoch, err := m.dial(t).SlotAndEpoch(context.Background())
+	if err != nil {
+		t.Fatalf("SlotAndEpoch: %v", err)
+	}
+	if slot != 7000000 || epoch != 218750 {
+		t.Fatalf("slot, epoch = %d, %d; want 7000000, 218750", slot, epoch)
+	}
+
+	custom := BeaconConfig{GenesisTime: time.Unix(1606824023+84000000, 0), SlotDuration: 6 * time.Second, SlotsPerEpoch: 8}
+	slot, epoch, err = m.dial(t, WithBeaconConfig(custom)).SlotAndEpoch(context.Background())
+	if err != nil {
+		t.Fatalf("SlotAndEpoch with custom config: %v", err)
+	}
+	if slot != 0 || epoch != 0 {
+		t.Fatalf("slot, epoch = %d, %d; want 0, 0", slot, epoch)
+	}
+}
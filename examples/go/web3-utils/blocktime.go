@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ErrBeforeGenesis is returned for times before the genesis block
+var ErrBeforeGenesis = errors.New("time is before the genesis block")
+
+// headerAt fetches the header of block number
+func (w *Web3Utils) headerAt(ctx context.Context, number uint64) (*types.Header, error) {
+	var header *types.Header
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		header, err = c.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header %d: %w", number, err)
+	}
+	return header, nil
+}
+
+// firstBlockAtOrAfter binary-searches block timestamps for the first block
+// at or after t, up to head. It returns head+1 if every block is older.
+func (w *Web3Utils) firstBlockAtOrAfter(ctx context.Context, t time.Time, head uint64) (uint64, error) {
+	lo, hi := uint64(0), head+1
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		header, err := w.headerAt(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if time.Unix(int64(header.Time), 0).Before(t) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// lastBlockAtOrBefore returns the last block up to head mined at or before t
+func (w *Web3Utils) lastBlockAtOrBefore(ctx context.Context, t time.Time, head uint64) (uint64, error) {
+	// Timestamps have second precision, so the first block after t is the
+	// first one at or after the next whole second
+	after, err := w.firstBlockAtOrAfter(ctx, t.Truncate(time.Second).Add(time.Second), head)
+	if err != nil {
+		return 0, err
+	}
+	if after == 0 {
+		return 0, ErrBeforeGenesis
+	}
+	return after - 1, nil
+}
+
+// BlockNumberAtTime returns the block that was the chain head at time t: the
+// last block mined at or before t, found by binary search over block
+// timestamps. Times after the latest block return the latest block; times
+// before genesis return ErrBeforeGenesis.
+func (w *Web3Utils) BlockNumberAtTime(ctx context.Context, t time.Time) (uint64, error) {
+	head, err := w.GetBlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return w.lastBlockAtOrBefore(ctx, t, head)
+}
+
+// TimeSinceLastBlock returns how long ago the latest block was mined, by its
+// timestamp. Clock skew between this host and the block producer can make
+// it slightly off, and it is zero for a timestamp in the future.
+func (w *Web3Utils) TimeSinceLastBlock(ctx context.Context) (time.Duration, error) {
+	header, err := w.latestHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	since := time.Since(time.Unix(int64(header.Time), 0))
+	if since < 0 {
+		return 0, nil
+	}
+	return since, nil
+}
+
+// IsChainHalted reports whether no new block has appeared within threshold,
+// as happens when an L2 sequencer or a testnet stalls. threshold should
+// allow for several normal block times so ordinary variance is not mistaken
+// for a halt.
+func (w *Web3Utils) IsChainHalted(ctx context.Context, threshold time.Duration) (bool, error) {
+	since, err := w.TimeSinceLastBlock(ctx)
+	if err != nil {
+		return false, err
+	}
+	return since > threshold, nil
+}
+
+// DefaultBlockTimeSample is the number of recent blocks the default block
+// time estimator averages over
+const DefaultBlockTimeSample = 100
+
+// BlockTimeEstimator estimates the average time between blocks of a chain.
+// The default samples recent headers, which assumes roughly uniform block
+// spacing; chains that produce blocks irregularly can supply their own with
+// WithBlockTimeEstimator.
+type BlockTimeEstimator interface {
+	AverageBlockTime(ctx context.Context) (time.Duration, error)
+}
+
+// HeaderSamplingEstimator is the default BlockTimeEstimator. It divides the
+// time spanned by the most recent blocks by their count.
+type HeaderSamplingEstimator struct {
+	utils  *Web3Utils
+	blocks uint64
+}
+
+// NewHeaderSamplingEstimator creates an estimator averaging over the last
+// blocks blocks of the chain utils is connected to
+func NewHeaderSamplingEstimator(utils *Web3Utils, blocks int) *HeaderSamplingEstimator {
+	if blocks < 1 {
+		blocks = 1
+	}
+	return &HeaderSamplingEstimator{utils: utils, blocks: uint64(blocks)}
+}
+
+// AverageBlockTime returns the mean interval between the sampled blocks
+func (e *HeaderSamplingEstimator) AverageBlockTime(ctx context.Context) (time.Duration, error) {
+	head, err := e.utils.latestHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	latest := head.Number.Uint64()
+	if latest == 0 {
+		return 0, fmt.Errorf("chain has no blocks after genesis")
+	}
+	span := e.blocks
+	if span > latest {
+		span = latest
+	}
+	oldest, err := e.utils.headerAt(ctx, latest-span)
+	if err != nil {
+		return 0, err
+	}
+	elapsed := time.Duration(head.Time-oldest.Time) * time.Second
+	return elapsed / time.Duration(span), nil
+}
+
+// AverageBlockTime estimates the time between blocks with the configured
+// BlockTimeEstimator, by default sampling the last DefaultBlockTimeSample
+// headers
+func (w *Web3Utils) AverageBlockTime(ctx context.Context) (time.Duration, error) {
+	if w.blockTime != nil {
+		return w.blockTime.AverageBlockTime(ctx)
+	}
+	return NewHeaderSamplingEstimator(w, DefaultBlockTimeSample).AverageBlockTime(ctx)
+}
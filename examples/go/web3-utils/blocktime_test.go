@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// mockChainTimes serves a chain of head+1 blocks where block n was mined at
+// genesis + n*12 seconds
+func mockChainTimes(m *mockRPC, genesis, head uint64) {
+	m.result("eth_blockNumber", hexutil.Uint64(head))
+	m.handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		n, ok := blockTag(params[0])
+		if !ok {
+			n = head
+		}
+		if n > head {
+			return nil, nil
+		}
+		return mockBlock(&types.Header{Number: new(big.Int).SetUint64(n), Time: genesis + n*12}), nil
+	})
+}
+
+func TestBlockNumberAtTime(t *testing.T) {
+	const genesis = 1700000000
+	m := newMockRPC(t)
+	mockChainTimes(m, genesis, 1000)
+	w := m.dial(t)
+
+	for _, offset := range []uint64{0, 1, 11, 12, 6005, 11999, 12000} {
+		target := time.Unix(genesis+int64(offset), 0)
+		n, err := w.BlockNumberAtTime(context.Background(), target)
+		if err != nil {
+			t.Fatalf("BlockNumberAtTime(+%ds): %v", offset, err)
+		}
+		// Block n must bracket the target: mined at or before it, with the
+		// next block mined after it
+		if mined := genesis + n*12; mined > genesis+offset || mined+12 <= genesis+offset {
+			t.Fatalf("BlockNumberAtTime(+%ds) = %d, mined at +%ds", offset, n, n*12)
+		}
+	}
+
+	n, err := w.BlockNumberAtTime(context.Background(), time.Unix(genesis+1000*12+3600, 0))
+	if err != nil || n != 1000 {
+		t.Fatalf("BlockNumberAtTime(future) = %d, %v; want latest block 1000", n, err)
+	}
+	if _, err := w.BlockNumberAtTime(context.Background(), time.Unix(genesis-1, 0)); !errors.Is(err, ErrBeforeGenesis) {
+		t.Fatalf("err = %v, want ErrBeforeGenesis", err)
+	}
+}
+
+func TestIsChainHalted(t *testing.T) {
+	tests := map[string]struct {
+		age    time.Duration
+		halted bool
+	}{
+		"stale": {10 * time.Minute, true},
+		"fresh": {5 * time.Second, false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newMockRPC(t)
+			mined := uint64(time.Now().Add(-tt.age).Unix())
+			m.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(100), Time: mined}))
+			w := m.dial(t)
+
+			since, err := w.TimeSinceLastBlock(context.Background())
+			if err != nil {
+				t.Fatalf("TimeSinceLastBlock: %v", err)
+			}
+			if since < tt.age-time.Second || since > tt.age+time.Minute {
+				t.Fatalf("TimeSinceLastBlock = %v, want about %v", since, tt.age)
+			}
+			halted, err := w.IsChainHalted(context.Background(), time.Minute)
+			if err != nil {
+				t.Fatalf("IsChainHalted: %v", err)
+			}
+			if halted != tt.halted {
+				t.Fatalf("IsChainHalted = %v, want %v", halted, tt.halted)
+			}
+		})
+	}
+}
+
+func TestAverageBlockTime(t *testing.T) {
+	m := newMockRPC(t)
+	mockChainTimes(m, 1700000000, 1000)
+	got, err := m.dial(t).AverageBlockTime(context.Background())
+	if err != nil {
+		t.Fatalf("AverageBlockTime: %v", err)
+	}
+	if got != 12*time.Second {
+		t.Fatalf("AverageBlockTime = %v, want 12s", got)
+	}
+
+	// A chain shorter than the sample averages over all of it
+	m = newMockRPC(t)
+	mockChainTimes(m, 1700000000, 5)
+	if got, err := m.dial(t).AverageBlockTime(context.Background()); err != nil || got != 12*time.Second {
+		t.Fatalf("AverageBlockTime(short chain) = %v, %v; want 12s", got, err)
+	}
+}
+
+// fixedBlockTime is a BlockTimeEstimator for chains with a known cadence
+type fixedBlockTime time.Duration
+
+func (f fixedBlockTime) AverageBlockTime(context.Context) (time.Duration, error) {
+	return time.Duration(f), nil
+}
+
+func TestBlockTimeEstimatorOption(t *testing.T) {
+	m := newMockRPC(t)
+	mockLinearTips(m)
+	w := m.dial(t, WithBlockTimeEstimator(fixedBlockTime(2*time.Second)))
+
+	got, err := w.AverageBlockTime(context.Background())
+	if err != nil || got != 2*time.Second {
+		t.Fatalf("AverageBlockTime = %v, %v; want 2s", got, err)
+	}
+	// A 15 gwei max fee clears half of each block, so it takes two blocks
+	wait, err := w.ExpectedInclusionTime(context.Background(), gwei(15))
+	if err != nil {
+		t.Fatalf("ExpectedInclusionTime: %v", err)
+	}
+	if wait != 4*time.Second {
+		t.Fatalf("ExpectedInclusionTime = %v, want 4s", wait)
+	}
+	if n := m.callCount("eth_getBlockByNumber"); n != 0 {
+		t.Fatalf("sampled %d headers despite the custom estimator", n)
+	}
+	if _, err := w.ExpectedInclusionTime(context.Background(), gwei(5)); err == nil {
+		t.Fatal("expected error for a max fee below every included price")
+	}
+}
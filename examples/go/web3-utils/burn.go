@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BlocksPerDay is the number of 12-second slots in a day on mainnet
+const BlocksPerDay = 24 * 60 * 60 / 12
+
+// BlockBurnedFees returns the ETH burned by a block under EIP-1559, i.e.
+// baseFee * gasUsed, or zero for pre-London blocks
+func BlockBurnedFees(header *types.Header) *big.Int {
+	if header.BaseFee == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Mul(header.BaseFee, new(big.Int).SetUint64(header.GasUsed))
+}
+
+// DailyBurnRate estimates the Wei burned per day by averaging the burn of the
+// last sampleBlocks blocks and scaling it to BlocksPerDay
+func (w *Web3Utils) DailyBurnRate(ctx context.Context, sampleBlocks int) (*big.Int, error) {
+	if sampleBlocks < 1 {
+		return nil, fmt.Errorf("sampleBlocks must be positive, got %d", sampleBlocks)
+	}
+	head, err := w.GetBlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(sampleBlocks) > head+1 {
+		sampleBlocks = int(head + 1)
+	}
+
+	burned := new(big.Int)
+	for num := head + 1 - uint64(sampleBlocks); num <= head; num++ {
+		var header *types.Header
+		err := w.call(ctx, func(c *ethclient.Client) (err error) {
+			header, err = c.HeaderByNumber(ctx, new(big.Int).SetUint64(num))
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get header %d: %w", num, err)
+		}
+		burned.Add(burned, BlockBurnedFees(header))
+	}
+
+	burned.Mul(burned, big.NewInt(BlocksPerDay))
+	return burned.Div(burned, big.NewInt(int64(sampleBlocks))), nil
+}
+
+// NetIssuance compares daily ETH issuance with the daily base-fee burn
+type NetIssuance struct {
+	DailyIssuance *big.Int
+	DailyBurn     *big.Int
+	// Net is DailyIssuance - DailyBurn; negative means supply is shrinking
+	Net *big.Int
+}
+
+// Deflationary reports whether more ETH is burned than issued
+func (n *NetIssuance) Deflationary() bool {
+	return n.Net.Sign() < 0
+}
+
+// newNetIssuance computes net issuance from daily issuance and burn
+func newNetIssuance(dailyIssuance, dailyBurn *big.Int) *NetIssuance {
+	return &NetIssuance{
+		DailyIssuance: new(big.Int).Set(dailyIssuance),
+		DailyBurn:     new(big.Int).Set(dailyBurn),
+		Net:           new(big.Int).Sub(dailyIssuance, dailyBurn),
+	}
+}
+
+// NetIssuanceEstimate estimates net daily ETH issuance by subtracting the
+// DailyBurnRate over sampleBlocks from dailyIssuanceWei. Staking issuance
+// depends on the amount staked and changes over time, so it is supplied by
+// the caller (roughly 2,700 ETH/day with ~34M ETH staked).
+func (w *Web3Utils) NetIssuanceEstimate(ctx context.Context, dailyIssuanceWei *big.Int, sampleBlocks int) (*NetIssuance, error) {
+	burn, err := w.DailyBurnRate(ctx, sampleBlocks)
+	if err != nil {
+		return nil, err
+	}
+	return newNetIssuance(dailyIssuanceWei, burn), nil
+}
+
+// RangeBurnAndTips sums the ETH burned through the base fee and the ETH paid
+// to validators as priority fees over blocks from to to, inclusive. Tips are
+// each transaction's effective gas price above the base fee times the gas it
+// used, read from the block's receipts. Before London all fees were tips.
+func (w *Web3Utils) RangeBurnAndTips(ctx context.Context, from, to uint64) (burned, tipped *big.Int, err error) {
+	if from > to {
+		return nil, nil, fmt.Errorf("invalid block range %d-%d", from, to)
+	}
+
+	burned, tipped = new(big.Int), new(big.Int)
+	for num := from; num <= to; num++ {
+		var (
+			block    *types.Block
+			receipts []*types.Receipt
+		)
+		err := w.call(ctx, func(c *ethclient.Client) (err error) {
+			number := new(big.Int).SetUint64(num)
+			if block, err = c.BlockByNumber(ctx, number); err != nil {
+				return err
+			}
+			receipts, err = c.BlockReceipts(ctx, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(num)))
+			return err
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get block %d: %w", num, err)
+		}
+
+		gasUsed := make(map[common.Hash]uint64, len(receipts))
+		for _, r := range receipts {
+			gasUsed[r.TxHash] = r.GasUsed
+		}
+		baseFee := block.BaseFee()
+		for _, tx := range block.Transactions() {
+			used, ok := gasUsed[tx.Hash()]
+			if !ok {
+				return nil, nil, fmt.Errorf("missing receipt for tx %s in block %d", tx.Hash().Hex(), num)
+			}
+			tip := EffectiveGasPrice(tx, baseFee)
+			if baseFee != nil {
+				tip.Sub(tip, baseFee)
+			}
+			tipped.Add(tipped, tip.Mul(tip, new(big.Int).SetUint64(used)))
+		}
+		burned.Add(burned, BlockBurnedFees(block.Header()))
+
+		if num == to {
+			break
+		}
+	}
+	return burned, tipped, nil
+}
+
+// BlockTransferVolume sums the value of every transaction in a block.
+// nativeTransfers counts only the transactions that move ETH, leaving out
+// zero-value contract calls.
+func BlockTransferVolume(block *types.Block) (value *big.Int, nativeTransfers int) {
+	value = new(big.Int)
+	for _, tx := range block.Transactions() {
+		if tx.Value().Sign() > 0 {
+			value.Add(value, tx.Value())
+			nativeTransfers++
+		}
+	}
+	return value, nativeTransfers
+}
+
+// BlockValueTransferred returns the total ETH value moved by the
+// transactions of a block, or the latest block if number is nil
+func (w *Web3Utils) BlockValueTransferred(ctx context.Context, number *big.Int) (*big.Int, error) {
+	var block *types.Block
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		block, err = c.BlockByNumber(ctx, number)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %s: %w", blockArg(number), err)
+	}
+	value, _ := BlockTransferVolume(block)
+	return value, nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func eth(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+}
+
+func TestBlockBurnedFees(t *testing.T) {
+	header := &types.Header{BaseFee: gwei(20), GasUsed: 15_000_000}
+	if got, want := BlockBurnedFees(header), new(big.Int).Mul(gwei(20), big.NewInt(15_000_000)); got.Cmp(want) != 0 {
+		t.Fatalf("burned = %s, want %s", got, want)
+	}
+	if got := BlockBurnedFees(&types.Header{GasUsed: 1}); got.Sign() != 0 {
+		t.Fatalf("pre-London burned = %s, want 0", got)
+	}
+}
+
+func TestNetIssuance(t *testing.T) {
+	inflationary := newNetIssuance(eth(2700), eth(1000))
+	if inflationary.Net.Cmp(eth(1700)) != 0 || inflationary.Deflationary() {
+		t.Fatalf("net = %s, want +1700 ETH", inflationary.Net)
+	}
+	deflationary := newNetIssuance(eth(2700), eth(3000))
+	if deflationary.Net.Cmp(eth(-300)) != 0 || !deflationary.Deflationary() {
+		t.Fatalf("net = %s, want -300 ETH", deflationary.Net)
+	}
+}
+
+func TestNetIssuanceEstimate(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_blockNumber", "0x64")
+	m.handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		num, _ := blockTag(params[0])
+		// alternate 0.1 and 0.3 ETH burned per block: 0.2 ETH average
+		fee := gwei(10)
+		if num%2 == 0 {
+			fee = gwei(30)
+		}
+		return mockBlock(&types.Header{Number: new(big.Int).SetUint64(num), BaseFee: fee, GasUsed: 10_000_000}), nil
+	})
+
+	est, err := m.dial(t).NetIssuanceEstimate(context.Background(), eth(2700), 4)
+	if err != nil {
+		t.Fatalf("NetIssuanceEstimate: %v", err)
+	}
+	// 0.2 ETH * 7200 blocks = 1440 ETH/day
+	if est.DailyBurn.Cmp(eth(1440)) != 0 {
+		t.Fatalf("daily burn = %s, want 1440 ETH", est.DailyBurn)
+	}
+	if est.Net.Cmp(eth(1260)) != 0 {
+		t.Fatalf("net = %s, want 1260 ETH", est.Net)
+	}
+}
+
+func TestRangeBurnAndTips(t *testing.T) {
+	router := common.HexToAddress(testAddress)
+	// Block 10: base fee 20 gwei, two txs tipping 2 and 5 gwei (the second
+	// capped by its fee cap); block 11: base fee 30 gwei, one 1 gwei tip
+	blocks := map[uint64][]*types.Transaction{
+		10: {dynamicTx(t, 0, router, gwei(2), gwei(50)), dynamicTx(t, 1, router, gwei(8), gwei(25))},
+		11: {dynamicTx(t, 2, router, gwei(1), gwei(60))},
+	}
+	baseFees := map[uint64]*big.Int{10: gwei(20), 11: gwei(30)}
+	used := []uint64{21000, 100000, 50000}
+
+	m := newMockRPC(t)
+	m.handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		n, _ := blockTag(params[0])
+		return mockBlock(&types.Header{Number: new(big.Int).SetUint64(n), BaseFee: baseFees[n], GasUsed: 1_000_000}, blocks[n]...), nil
+	})
+	m.handle("eth_getBlockReceipts", func(params []json.RawMessage) (interface{}, error) {
+		n, _ := blockTag(params[0])
+		var receipts []*types.Receipt
+		for _, tx := range blocks[n] {
+			r := mockReceipt(tx.Hash().Hex(), int64(n), types.ReceiptStatusSuccessful)
+			r.GasUsed = used[tx.Nonce()]
+			receipts = append(receipts, r)
+		}
+		return receipts, nil
+	})
+
+	burned, tipped, err := m.dial(t).RangeBurnAndTips(context.Background(), 10, 11)
+	if err != nil {
+		t.Fatalf("RangeBurnAndTips: %v", err)
+	}
+	// 1M gas at 20 gwei + 1M gas at 30 gwei
+	if want := gwei(50_000_000); burned.Cmp(want) != 0 {
+		t.Fatalf("burned = %s, want %s", burned, want)
+	}
+	// 21000*2 + 100000*5 + 50000*1 gwei
+	if want := gwei(21000*2 + 100000*5 + 50000); tipped.Cmp(want) != 0 {
+		t.Fatalf("tipped = %s, want %s", tipped, want)
+	}
+}
+
+func TestBlockValueTransferred(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	to := common.HexToAddress(testAddress)
+	transfer := func(nonce uint64, value *big.Int) *types.Transaction {
+		tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID: big.NewInt(1), Nonce: nonce, GasTipCap: gwei(1), GasFeeCap: gwei(30), Gas: 21000, To: &to, Value: value,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	// Two transfers and a zero-value contract call
+	txs := []*types.Transaction{transfer(0, eth(2)), transfer(1, gwei(500_000_000)), dynamicTx(t, 2, to, gwei(1), gwei(30))}
+
+	m := newMockRPC(t)
+	m.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(100)}, txs...))
+
+	value, err := m.dial(t).BlockValueTransferred(context.Background(), big.NewInt(100))
+	if err != nil {
+		t.Fatalf("BlockValueTransferred: %v", err)
+	}
+	if want := new(big.Int).Add(eth(2), gwei(500_000_000)); value.Cmp(want) != 0 {
+		t.Fatalf("value = %s, want %s", value, want)
+	}
+
+	block := types.NewBlockWithHeader(&types.Header{}).WithBody(txs, nil)
+	if _, n := BlockTransferVolume(block); n != 2 {
+		t.Fatalf("native transfers = %d, want 2", n)
+	}
+}
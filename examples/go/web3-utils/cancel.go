@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ReplacementBumpPercent is the minimum fee increase, in percent, nodes
+// require before they accept a transaction replacing a pending one
+const ReplacementBumpPercent = 10
+
+// bumpFee returns fee increased by percent, rounded up
+func bumpFee(fee *big.Int, percent int64) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Quo(bumped, big.NewInt(100))
+}
+
+// maxBig returns the larger of a and b
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// ReplacementFee returns the minimum fees a transaction must pay to replace
+// the pending transaction txHash: its max fee and tip raised by
+// ReplacementBumpPercent. For legacy transactions the tip is nil and maxFee
+// is the bumped gas price.
+func (w *Web3Utils) ReplacementFee(ctx context.Context, txHash string) (maxFee, tip *big.Int, err error) {
+	tx, isPending, err := w.GetTransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isPending {
+		return nil, nil, fmt.Errorf("transaction %s is not pending", txHash)
+	}
+
+	maxFee = bumpFee(tx.GasFeeCap(), ReplacementBumpPercent)
+	if tx.Type() == types.LegacyTxType || tx.Type() == types.AccessListTxType {
+		return maxFee, nil, nil
+	}
+	return maxFee, bumpFee(tx.GasTipCap(), ReplacementBumpPercent), nil
+}
+
+// CancelAllPending cancels every pending transaction of the key's account by
+// broadcasting a 0-value self-transfer at each pending nonce. Each
+// replacement pays the currently suggested fees or, when the node exposes its
+// pool through txpool_contentFrom, at least ReplacementBumpPercent more than
+// the transaction it replaces. Nodes without the txpool namespace only get
+// the suggested fees, so replacements of transactions that were sent above
+// them may be rejected as underpriced. It returns the cancellation hashes in
+// nonce order, or none if nothing is pending.
+func (w *Web3Utils) CancelAllPending(ctx context.Context, privateKey *ecdsa.PrivateKey) ([]common.Hash, error) {
+	from := PrivateKeyToAddress(privateKey)
+
+	var latest, pending uint64
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		if latest, err = c.NonceAt(ctx, from, nil); err != nil {
+			return err
+		}
+		pending, err = c.PendingNonceAt(ctx, from)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonces: %w", err)
+	}
+	if pending <= latest {
+		return nil, nil
+	}
+
+	chainID, err := w.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	maxFee, tip, err := w.SuggestGasFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	legacy := tip == nil
+
+	// Existing pool entries, keyed by nonce, if the node exposes them
+	var content struct {
+		Pending map[string]*types.Transaction `json:"pending"`
+	}
+	err = w.call(ctx, func(c *ethclient.Client) error {
+		return c.Client().CallContext(ctx, &content, "txpool_contentFrom", from)
+	})
+	if err != nil && !isMethodNotFound(err) {
+		return nil, fmt.Errorf("failed to get txpool content: %w", err)
+	}
+
+	signer := types.LatestSignerForChainID(chainID)
+	hashes := make([]common.Hash, 0, pending-latest)
+	for nonce := latest; nonce < pending; nonce++ {
+		txFee, txTip := maxFee, tip
+		if old := content.Pending[fmt.Sprint(nonce)]; old != nil {
+			txFee = maxBig(txFee, bumpFee(old.GasFeeCap(), ReplacementBumpPercent))
+			if !legacy {
+				txTip = maxBig(txTip, bumpFee(old.GasTipCap(), ReplacementBumpPercent))
+				txFee = maxBig(txFee, txTip)
+			}
+		}
+
+		var data types.TxData
+		if legacy {
+			data = &types.LegacyTx{Nonce: nonce, GasPrice: txFee, Gas: params.TxGas, To: &from, Value: new(big.Int)}
+		} else {
+			data = &types.DynamicFeeTx{ChainID: chainID, Nonce: nonce, GasTipCap: txTip, GasFeeCap: txFee, Gas: params.TxGas, To: &from, Value: new(big.Int)}
+		}
+		tx, err := types.SignNewTx(privateKey, signer, data)
+		if err != nil {
+			return hashes, fmt.Errorf("failed to sign cancellation for nonce %d: %w", nonce, err)
+		}
+		err = w.call(ctx, func(c *ethclient.Client) error {
+			return c.SendTransaction(ctx, tx)
+		})
+		if err != nil {
+			return hashes, fmt.Errorf("failed to send cancellation for nonce %d: %w", nonce, err)
+		}
+		hashes = append(hashes, tx.Hash())
+	}
+	return hashes, nil
+}
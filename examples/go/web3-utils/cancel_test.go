@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCancelAllPending(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := PrivateKeyToAddress(key)
+
+	m := newMockRPC(t)
+	mockNonces(m, 5, 7)
+	m.result("eth_chainId", "0x1")
+	m.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(100), BaseFee: gwei(30)}))
+	m.result("eth_maxPriorityFeePerGas", hexutil.Big(*gwei(2)))
+	// The stuck tx at nonce 6 already pays a 5 gwei tip
+	stuck := dynamicTx(t, 6, from, gwei(5), gwei(100))
+	m.result("txpool_contentFrom", map[string]interface{}{
+		"pending": map[string]interface{}{"6": rpcTxJSON(t, stuck, nil)},
+		"queued":  map[string]interface{}{},
+	})
+
+	var sent []*types.Transaction
+	m.handle("eth_sendRawTransaction", func(params []json.RawMessage) (interface{}, error) {
+		var raw hexutil.Bytes
+		if err := json.Unmarshal(params[0], &raw); err != nil {
+			return nil, err
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, err
+		}
+		sent = append(sent, tx)
+		return tx.Hash(), nil
+	})
+
+	hashes, err := m.dial(t).CancelAllPending(context.Background(), key)
+	if err != nil {
+		t.Fatalf("CancelAllPending: %v", err)
+	}
+	if len(hashes) != 2 || len(sent) != 2 {
+		t.Fatalf("got %d hashes and %d sent txs, want 2", len(hashes), len(sent))
+	}
+	for i, tx := range sent {
+		if tx.Hash() != hashes[i] {
+			t.Errorf("hash %d = %s, want %s", i, hashes[i].Hex(), tx.Hash().Hex())
+		}
+		if tx.Nonce() != uint64(5+i) {
+			t.Errorf("tx %d nonce = %d, want %d", i, tx.Nonce(), 5+i)
+		}
+		if *tx.To() != from || tx.Value().Sign() != 0 {
+			t.Errorf("tx %d is not a 0-value self-transfer", i)
+		}
+		if ok, err := VerifyTxSigner(tx, from); err != nil || !ok {
+			t.Errorf("tx %d not signed by the account", i)
+		}
+	}
+	if sent[0].GasTipCap().Cmp(gwei(2)) != 0 {
+		t.Errorf("nonce 5 tip = %v, want suggested %v", sent[0].GasTipCap(), gwei(2))
+	}
+	if want := big.NewInt(5500000000); sent[1].GasTipCap().Cmp(want) != 0 {
+		t.Errorf("nonce 6 tip = %v, want bumped %v", sent[1].GasTipCap(), want)
+	}
+	if want := gwei(110); sent[1].GasFeeCap().Cmp(want) != 0 {
+		t.Errorf("nonce 6 fee cap = %v, want bumped %v", sent[1].GasFeeCap(), want)
+	}
+}
+
+func TestCancelAllPendingNothingPending(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newMockRPC(t)
+	mockNonces(m, 3, 3)
+
+	hashes, err := m.dial(t).CancelAllPending(context.Background(), key)
+	if err != nil {
+		t.Fatalf("CancelAllPending: %v", err)
+	}
+	if len(hashes) != 0 {
+		t.Fatalf("got %d cancellations, want none", len(hashes))
+	}
+	if n := m.callCount("eth_sendRawTransaction"); n != 0 {
+		t.Fatalf("eth_sendRawTransaction called %d times, want 0", n)
+	}
+}
+
+func TestCancelAllPendingTxpoolErrors(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		txpool  rpcHandler
+		wantErr bool
+	}{
+		// The mock answers -32601 for unregistered methods
+		{name: "no txpool namespace"},
+		{
+			name: "txpool failure",
+			txpool: func([]json.RawMessage) (interface{}, error) {
+				return nil, &rpcError{code: -32000, msg: "txpool unavailable"}
+			},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newMockRPC(t)
+			mockNonces(m, 5, 6)
+			m.result("eth_chainId", "0x1")
+			m.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(100), BaseFee: gwei(30)}))
+			m.result("eth_maxPriorityFeePerGas", hexutil.Big(*gwei(2)))
+			m.result("eth_sendRawTransaction", common.Hash{})
+			if tc.txpool != nil {
+				m.handle("txpool_contentFrom", tc.txpool)
+			}
+
+			hashes, err := m.dial(t).CancelAllPending(context.Background(), key)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected the txpool error to be returned")
+				}
+				if n := m.callCount("eth_sendRawTransaction"); n != 0 {
+					t.Fatalf("eth_sendRawTransaction called %d times, want 0", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CancelAllPending: %v", err)
+			}
+			if len(hashes) != 1 {
+				t.Fatalf("got %d cancellations, want 1", len(hashes))
+			}
+		})
+	}
+}
+
+func TestReplacementFee(t *testing.T) {
+	tx := dynamicTx(t, 0, common.HexToAddress(testAddress), gwei(2), gwei(50))
+	m := newMockRPC(t)
+	m.result("eth_getTransactionByHash", tx)
+
+	maxFee, tip, err := m.dial(t).ReplacementFee(context.Background(), tx.Hash().Hex())
+	if err != nil {
+		t.Fatalf("ReplacementFee: %v", err)
+	}
+	if maxFee.Cmp(gwei(55)) != 0 {
+		t.Fatalf("max fee = %s, want 55 gwei", maxFee)
+	}
+	if tip.Cmp(new(big.Int).Div(gwei(22), big.NewInt(10))) != 0 {
+		t.Fatalf("tip = %s, want 2.2 gwei", tip)
+	}
+}
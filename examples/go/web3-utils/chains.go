@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DefaultBaseFeeBufferPercent is how far above the latest base fee
+// SuggestGasFees sets the max fee by default: 100% doubles the base fee
+const DefaultBaseFeeBufferPercent = 100
+
+// networkNameTimeout bounds the chain ID lookup made by NetworkName
+const networkNameTimeout = 5 * time.Second
+
+// ChainProfile holds the default gas and confirmation settings for a chain
+type ChainProfile struct {
+	Name string
+	// MinTip is the priority fee floor used by SuggestGasFees, nil for none
+	MinTip *big.Int
+	// BaseFeeBufferPercent is how far above the base fee the suggested max
+	// fee is set
+	BaseFeeBufferPercent uint64
+	// ConfirmationDepth is the confirmation count considered final
+	ConfirmationDepth uint64
+	// BlockTime is the expected time between blocks, used as poll interval
+	BlockTime time.Duration
+}
+
+// ChainProfiles maps chain IDs to the profile applied once the connected
+// chain is known, on the first successful ChainID lookup. Entries may be
+// added or replaced before creating a Web3Utils.
+var ChainProfiles = map[uint64]ChainProfile{
+	1:    {Name: "Ethereum", BaseFeeBufferPercent: 100, ConfirmationDepth: 12, BlockTime: 12 * time.Second},
+	10:   {Name: "Optimism", MinTip: big.NewInt(1_000_000), BaseFeeBufferPercent: 25, ConfirmationDepth: 10, BlockTime: 2 * time.Second},
+	137:  {Name: "Polygon", MinTip: big.NewInt(30_000_000_000), BaseFeeBufferPercent: 100, ConfirmationDepth: 128, BlockTime: 2 * time.Second},
+	8453: {Name: "Base", MinTip: big.NewInt(1_000_000), BaseFeeBufferPercent: 25, ConfirmationDepth: 10, BlockTime: 2 * time.Second},
+	// Arbitrum ignores the priority fee and its sequencer orders
+	// transactions once accepted, so one confirmation is as good as many
+	// until the batch settles on L1.
+	42161:    {Name: "Arbitrum One", BaseFeeBufferPercent: 25, ConfirmationDepth: 1, BlockTime: 250 * time.Millisecond},
+	11155111: {Name: "Sepolia", BaseFeeBufferPercent: 100, ConfirmationDepth: 12, BlockTime: 12 * time.Second},
+}
+
+// networkNames maps chain IDs to the short names returned by NetworkName
+var networkNames = map[uint64]string{
+	1:        "mainnet",
+	10:       "optimism",
+	56:       "bsc",
+	100:      "gnosis",
+	137:      "polygon",
+	324:      "zksync",
+	8453:     "base",
+	17000:    "holesky",
+	42161:    "arbitrum",
+	43114:    "avalanche",
+	59144:    "linea",
+	11155111: "sepolia",
+}
+
+// chainSettings are the settings a ChainProfile provides defaults for
+type chainSettings struct {
+	minTip             *big.Int
+	baseFeeBuffer      uint64
+	confirmationTarget uint64
+	pollInterval       time.Duration
+
+	// explicit marks the settings given as options, which take precedence
+	// over the chain profile
+	explicit struct {
+		minTip, baseFeeBuffer, confirmationTarget, pollInterval bool
+	}
+}
+
+// apply sets the profile's defaults on s, leaving explicit settings alone
+func (p ChainProfile) apply(s *chainSettings) {
+	if !s.explicit.minTip {
+		s.minTip = p.MinTip
+	}
+	if !s.explicit.baseFeeBuffer {
+		s.baseFeeBuffer = p.BaseFeeBufferPercent
+	}
+	if !s.explicit.confirmationTarget {
+		s.confirmationTarget = p.ConfirmationDepth
+	}
+	if !s.explicit.pollInterval {
+		s.pollInterval = p.BlockTime
+	}
+}
+
+// applyChainProfile applies the profile of chain id, if it has one
+func (w *Web3Utils) applyChainProfile(id *big.Int) {
+	if !id.IsUint64() {
+		return
+	}
+	profile, ok := ChainProfiles[id.Uint64()]
+	if !ok {
+		return
+	}
+	w.profileMu.Lock()
+	defer w.profileMu.Unlock()
+	profile.apply(&w.settings)
+}
+
+// currentSettings returns the settings in effect. Until a chain ID lookup
+// succeeds it tries one, so the chain profile is resolved lazily on first
+// use; while lookups fail the generic defaults apply.
+func (w *Web3Utils) currentSettings(ctx context.Context) chainSettings {
+	_, _ = w.ChainID(ctx)
+	w.profileMu.RLock()
+	defer w.profileMu.RUnlock()
+	return w.settings
+}
+
+// ChainID returns the chain ID of the connected network. It is fetched on
+// the first successful call, which also applies the chain's profile, and
+// cached for the lifetime of the instance; failed lookups are not cached.
+func (w *Web3Utils) ChainID(ctx context.Context) (*big.Int, error) {
+	w.chainIDMu.Lock()
+	defer w.chainIDMu.Unlock()
+	if w.chainID == nil {
+		var id *big.Int
+		err := w.call(ctx, func(c *ethclient.Client) (err error) {
+			id, err = c.ChainID(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get chain id: %w", err)
+		}
+		w.chainID = id
+		w.applyChainProfile(id)
+	}
+	return new(big.Int).Set(w.chainID), nil
+}
+
+// NetworkName returns a short name for the connected network, such as
+// "mainnet" or "sepolia", "unknown(<id>)" for chains missing from the list,
+// or "unknown" if the chain ID cannot be fetched
+func (w *Web3Utils) NetworkName() string {
+	ctx, cancel := context.WithTimeout(context.Background(), networkNameTimeout)
+	defer cancel()
+
+	id, err := w.ChainID(ctx)
+	if err != nil {
+		return "unknown"
+	}
+	if id.IsUint64() {
+		if name, ok := networkNames[id.Uint64()]; ok {
+			return name
+		}
+	}
+	return fmt.Sprintf("unknown(%s)", id)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChainProfileDefaults(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_chainId", "0xa4b1")
+
+	w := m.dial(t)
+	if n := m.callCount("eth_chainId"); n != 0 {
+		t.Fatalf("constructor called eth_chainId %d times, want lazy lookup", n)
+	}
+	s := w.currentSettings(context.Background())
+	if s.confirmationTarget != 1 || s.pollInterval != 250*time.Millisecond || s.baseFeeBuffer != 25 || s.minTip != nil {
+		t.Fatalf("arbitrum defaults = confirmations %d poll %v buffer %d min tip %v",
+			s.confirmationTarget, s.pollInterval, s.baseFeeBuffer, s.minTip)
+	}
+
+	// Explicit options win over the profile
+	w = m.dial(t, WithConfirmationTarget(20))
+	s = w.currentSettings(context.Background())
+	if s.confirmationTarget != 20 || s.pollInterval != 250*time.Millisecond {
+		t.Fatalf("with override = confirmations %d poll %v", s.confirmationTarget, s.pollInterval)
+	}
+}
+
+func TestChainProfileUnknownChain(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_chainId", "0x539")
+
+	s := m.dial(t).currentSettings(context.Background())
+	if s.confirmationTarget != DefaultConfirmationTarget || s.pollInterval != DefaultPollInterval || s.baseFeeBuffer != DefaultBaseFeeBufferPercent {
+		t.Fatalf("unknown chain defaults = confirmations %d poll %v buffer %d",
+			s.confirmationTarget, s.pollInterval, s.baseFeeBuffer)
+	}
+}
+
+func TestChainProfileAfterFailedLookup(t *testing.T) {
+	var calls atomic.Int64
+	m := newMockRPC(t)
+	m.handle("eth_chainId", func([]json.RawMessage) (interface{}, error) {
+		if calls.Add(1) == 1 {
+			return nil, &rpcError{code: -32000, msg: "temporarily unavailable"}
+		}
+		return "0xa4b1", nil
+	})
+	w := m.dial(t)
+
+	// The failed lookup leaves the generic defaults in place for now
+	if s := w.currentSettings(context.Background()); s.confirmationTarget != DefaultConfirmationTarget {
+		t.Fatalf("confirmations after failed lookup = %d, want default %d", s.confirmationTarget, DefaultConfirmationTarget)
+	}
+	// and the next use retries and applies the Arbitrum profile
+	if s := w.currentSettings(context.Background()); s.confirmationTarget != 1 || s.baseFeeBuffer != 25 {
+		t.Fatalf("after retry = confirmations %d buffer %d, want arbitrum profile", s.confirmationTarget, s.baseFeeBuffer)
+	}
+	w.currentSettings(context.Background())
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("eth_chainId called %d times, want 2", n)
+	}
+}
+
+func TestChainIDCached(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_chainId", "0xaa36a7")
+	w := m.dial(t)
+
+	for i := 0; i < 2; i++ {
+		id, err := w.ChainID(context.Background())
+		if err != nil {
+			t.Fatalf("ChainID: %v", err)
+		}
+		if id.Uint64() != 11155111 {
+			t.Fatalf("chain id = %s, want 11155111", id)
+		}
+	}
+	// The first lookup is cached
+	if n := m.callCount("eth_chainId"); n != 1 {
+		t.Fatalf("eth_chainId called %d times, want 1", n)
+	}
+	if got := w.NetworkName(); got != "sepolia" {
+		t.Fatalf("NetworkName = %q, want sepolia", got)
+	}
+}
+
+func TestNetworkNameUnknown(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_chainId", "0x539")
+	if got := m.dial(t).NetworkName(); got != "unknown(1337)" {
+		t.Fatalf("NetworkName = %q, want unknown(1337)", got)
+	}
+}
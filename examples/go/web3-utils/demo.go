@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// RunDemo walks through the main features of the package against the node
+// utils is connected to, writing the output to w. Failed network lookups are
+// reported in the output; an error is returned only if the demo cannot
+// continue.
+func RunDemo(w io.Writer, utils *Web3Utils) error {
+	ctx := context.Background()
+
+	fmt.Fprintln(w, "🔗 Web3 Go Utilities Demo")
+	fmt.Fprintln(w, strings.Repeat("=", 51))
+
+	// Get latest block number
+	blockNum, err := utils.GetBlockNumber(ctx)
+	if err != nil {
+		fmt.Fprintf(w, "Error getting block number: %v\n", err)
+	} else {
+		fmt.Fprintf(w, "\n📦 Latest Block: %d\n", blockNum)
+	}
+
+	// Get gas price
+	gasPrice, err := utils.GetGasPrice(ctx)
+	if err != nil {
+		fmt.Fprintf(w, "Error getting gas price: %v\n", err)
+	} else {
+		fmt.Fprintf(w, "⛽ Gas Price: %.2f Gwei\n", WeiToGwei(gasPrice))
+	}
+
+	// Generate new key pair
+	privateKey, err := GeneratePrivateKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate private key: %w", err)
+	}
+
+	address := PrivateKeyToAddress(privateKey)
+	privateKeyBytes := crypto.FromECDSA(privateKey)
+	privateKeyHex := hexutil.Encode(privateKeyBytes)
+
+	fmt.Fprintf(w, "\n🔑 Generated New Key Pair:\n")
+	fmt.Fprintf(w, "   Address: %s\n", address.Hex())
+	fmt.Fprintf(w, "   Private Key: %s\n", privateKeyHex)
+
+	// Sign and verify message
+	message := []byte("Hello, Web3!")
+	signature, err := SignMessage(message, privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign message: %w", err)
+	}
+
+	fmt.Fprintf(w, "\n✍️  Message Signature:\n")
+	fmt.Fprintf(w, "   Message: %s\n", string(message))
+	fmt.Fprintf(w, "   Signature: %s\n", hexutil.Encode(signature))
+
+	// Verify signature
+	isValid := VerifySignature(message, signature, address)
+	fmt.Fprintf(w, "   Valid: %v\n", isValid)
+
+	// Example: Check Vitalik's balance
+	vitalikAddress := "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"
+	balance, err := utils.GetBalance(ctx, vitalikAddress)
+	if err != nil {
+		fmt.Fprintf(w, "Error getting balance: %v\n", err)
+	} else {
+		ethBalance := WeiToEth(balance)
+		fmt.Fprintf(w, "\n💰 Vitalik's Balance:\n")
+		fmt.Fprintf(w, "   Address: %s\n", vitalikAddress)
+		fmt.Fprintf(w, "   Balance: %.4f ETH\n", ethBalance)
+	}
+	return nil
+}
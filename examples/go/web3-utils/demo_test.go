@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunDemo(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_blockNumber", "0x112a880")
+	m.result("eth_gasPrice", "0x4a817c800")          // 20 gwei
+	m.result("eth_getBalance", "0x1bc16d674ec80000") // 2 ETH
+
+	var out bytes.Buffer
+	if err := RunDemo(&out, m.dial(t)); err != nil {
+		t.Fatalf("RunDemo: %v", err)
+	}
+	for _, want := range []string{
+		"Latest Block: 18000000",
+		"Gas Price: 20.00 Gwei",
+		"Message: Hello, Web3!",
+		"Valid: true",
+		"Balance: 2.0000 ETH",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
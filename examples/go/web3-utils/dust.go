@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DefaultDustMinSenders is how many distinct senders must send an address
+// tiny amounts before DetectDust treats them as a dusting attack rather than
+// ordinary small payments, unless changed with WithDustMinSenders
+const DefaultDustMinSenders = 3
+
+// DetectDust scans blocks fromBlock to toBlock for native ETH transfers to
+// address worth more than zero but less than thresholdWei. Dusting attacks
+// send such amounts from many addresses to link the victim's accounts once
+// the dust is spent, so the transfers are only flagged when at least the
+// configured number of distinct senders are involved (WithDustMinSenders). The hashes are returned in
+// chain order, or nil if nothing looks like dusting. Every block in the
+// range is fetched, so keep the range small.
+func (w *Web3Utils) DetectDust(ctx context.Context, address string, thresholdWei *big.Int, fromBlock, toBlock uint64) ([]common.Hash, error) {
+	if fromBlock > toBlock {
+		return nil, fmt.Errorf("invalid block range %d-%d", fromBlock, toBlock)
+	}
+	if thresholdWei == nil || thresholdWei.Sign() <= 0 {
+		return nil, fmt.Errorf("threshold must be positive")
+	}
+	account := common.HexToAddress(address)
+
+	var dust []common.Hash
+	senders := make(map[common.Address]bool)
+	for num := fromBlock; num <= toBlock; num++ {
+		var block *types.Block
+		err := w.call(ctx, func(c *ethclient.Client) (err error) {
+			block, err = c.BlockByNumber(ctx, new(big.Int).SetUint64(num))
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block %d: %w", num, err)
+		}
+		for _, tx := range block.Transactions() {
+			if tx.To() == nil || *tx.To() != account || tx.Value().Sign() == 0 || tx.Value().Cmp(thresholdWei) >= 0 {
+				continue
+			}
+			sender, err := txSender(tx)
+			if err != nil || sender == account {
+				continue
+			}
+			senders[sender] = true
+			dust = append(dust, tx.Hash())
+		}
+		if num == toBlock {
+			break
+		}
+	}
+
+	if len(senders) < w.dustMinSenders {
+		return nil, nil
+	}
+	return dust, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDetectDust(t *testing.T) {
+	victim := common.HexToAddress(testAddress)
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	// Each legacyTx comes from a fresh sender
+	dust := []*types.Transaction{
+		legacyTx(t, 0, victim, big.NewInt(100), gwei(10)),
+		legacyTx(t, 0, victim, big.NewInt(500), gwei(10)),
+		legacyTx(t, 0, victim, big.NewInt(1), gwei(10)),
+	}
+	blocks := map[uint64][]*types.Transaction{
+		10: {dust[0], legacyTx(t, 0, victim, eth(1), gwei(10)), dust[1]},
+		11: {legacyTx(t, 0, other, big.NewInt(1), gwei(10)), dust[2]},
+	}
+
+	m := newMockRPC(t)
+	m.handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		n, _ := blockTag(params[0])
+		return mockBlock(&types.Header{Number: new(big.Int).SetUint64(n)}, blocks[n]...), nil
+	})
+	w := m.dial(t)
+
+	flagged, err := w.DetectDust(context.Background(), victim.Hex(), big.NewInt(1000), 10, 11)
+	if err != nil {
+		t.Fatalf("DetectDust: %v", err)
+	}
+	if len(flagged) != len(dust) {
+		t.Fatalf("flagged %d transfers, want %d", len(flagged), len(dust))
+	}
+	for i, tx := range dust {
+		if flagged[i] != tx.Hash() {
+			t.Errorf("flagged[%d] = %s, want %s", i, flagged[i].Hex(), tx.Hash().Hex())
+		}
+	}
+
+	// Two senders are not enough to call it dusting
+	flagged, err = w.DetectDust(context.Background(), victim.Hex(), big.NewInt(1000), 10, 10)
+	if err != nil {
+		t.Fatalf("DetectDust: %v", err)
+	}
+	if flagged != nil {
+		t.Fatalf("flagged %v from two senders, want nothing", flagged)
+	}
+
+	// unless fewer senders are configured
+	flagged, err = m.dial(t, WithDustMinSenders(2)).DetectDust(context.Background(), victim.Hex(), big.NewInt(1000), 10, 10)
+	if err != nil {
+		t.Fatalf("DetectDust: %v", err)
+	}
+	if len(flagged) != 2 {
+		t.Fatalf("flagged %d transfers with WithDustMinSenders(2), want 2", len(flagged))
+	}
+}
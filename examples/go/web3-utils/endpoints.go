@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// endpointSet holds the RPC endpoints of a Web3Utils instance. Clients are
+// dialed on first use and calls go to the active endpoint until it fails at
+// the connection level.
+type endpointSet struct {
+	urls []string
+	dial func(rpcURL string) (*ethclient.Client, error)
+	// websocket records which urls are ws:// or wss:// endpoints
+	websocket []bool
+
+	mu      sync.Mutex
+	clients []*ethclient.Client
+	active  int
+	closed  bool
+}
+
+func newEndpointSet(urls []string, dial func(string) (*ethclient.Client, error)) *endpointSet {
+	websocket := make([]bool, len(urls))
+	for i, url := range urls {
+		websocket[i] = isWebsocketURL(url)
+	}
+	return &endpointSet{urls: urls, dial: dial, websocket: websocket, clients: make([]*ethclient.Client, len(urls))}
+}
+
+// isWebsocketURL reports whether rpcURL uses the ws or wss scheme
+func isWebsocketURL(rpcURL string) bool {
+	scheme, _, _ := strings.Cut(rpcURL, "://")
+	scheme = strings.ToLower(scheme)
+	return scheme == "ws" || scheme == "wss"
+}
+
+// current returns the index of the active endpoint
+func (s *endpointSet) current() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active
+}
+
+// client returns the client of endpoint i, dialing it if needed
+func (s *endpointSet) client(i int) (*ethclient.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, ErrShutdown
+	}
+	if s.clients[i] == nil {
+		c, err := s.dial(s.urls[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", s.urls[i], err)
+		}
+		s.clients[i] = c
+	}
+	return s.clients[i], nil
+}
+
+// rotate moves past endpoint i after it failed. Concurrent calls failing on
+// the same endpoint rotate only once.
+func (s *endpointSet) rotate(i int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == i {
+		s.active = (i + 1) % len(s.urls)
+	}
+}
+
+// close closes every dialed client
+func (s *endpointSet) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for _, c := range s.clients {
+		if c != nil {
+			c.Close()
+		}
+	}
+}
+
+// isConnectionError reports whether err means the endpoint could not be
+// reached or failed to answer, as opposed to the node rejecting the request
+func isConnectionError(err error) bool {
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
+
+// NewWeb3UtilsWithEndpoints creates a Web3Utils instance that fails over
+// between several RPC endpoints. Endpoints are dialed lazily.
+// Calls go to the active endpoint; when one fails with a connection-level
+// error the next endpoint becomes active and the call is retried there, so a
+// call only fails once every endpoint has been tried.
+func NewWeb3UtilsWithEndpoints(urls []string, opts ...Option) (*Web3Utils, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no RPC endpoints given")
+	}
+	w := newWeb3Utils(append([]string(nil), urls...), opts)
+	return w, nil
+}
+
+// ActiveEndpoint returns the URL of the endpoint calls are currently sent to
+func (w *Web3Utils) ActiveEndpoint() string {
+	return w.endpoints.urls[w.endpoints.current()]
+}
+
+// callWithFailover runs fn against the active endpoint, moving on to the next
+// one whenever it fails at the connection level
+func (w *Web3Utils) callWithFailover(ctx context.Context, fn func(c *ethclient.Client) error) error {
+	var err error
+	for tried := 0; tried < len(w.endpoints.urls); tried++ {
+		i := w.endpoints.current()
+		var c *ethclient.Client
+		if c, err = w.endpoints.client(i); err == nil {
+			err = w.retryCall(ctx, c, fn)
+			if err == nil || !isConnectionError(err) {
+				return err
+			}
+		} else if errors.Is(err, ErrShutdown) {
+			return err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		w.endpoints.rotate(i)
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// deadEndpoint returns the URL of a server that refuses connections
+func deadEndpoint(t *testing.T) string {
+	t.Helper()
+	m := newMockRPC(t)
+	m.server.Close()
+	return m.server.URL
+}
+
+func dialEndpoints(t *testing.T, urls ...string) *Web3Utils {
+	t.Helper()
+	w, err := NewWeb3UtilsWithEndpoints(urls)
+	if err != nil {
+		t.Fatalf("NewWeb3UtilsWithEndpoints: %v", err)
+	}
+	t.Cleanup(w.Close)
+	return w
+}
+
+func TestEndpointFailover(t *testing.T) {
+	dead := deadEndpoint(t)
+	backup := newMockRPC(t)
+	backup.result("eth_blockNumber", "0x64")
+
+	w := dialEndpoints(t, dead, backup.server.URL)
+	n, err := w.GetBlockNumber(context.Background())
+	if err != nil {
+		t.Fatalf("GetBlockNumber: %v", err)
+	}
+	if n != 100 {
+		t.Fatalf("block = %d, want 100", n)
+	}
+	if w.ActiveEndpoint() != backup.server.URL {
+		t.Fatalf("active endpoint = %s, want %s", w.ActiveEndpoint(), backup.server.URL)
+	}
+
+	// Later calls stay on the healthy endpoint
+	if _, err := w.GetBlockNumber(context.Background()); err != nil {
+		t.Fatalf("GetBlockNumber: %v", err)
+	}
+	if got := backup.callCount("eth_blockNumber"); got != 2 {
+		t.Fatalf("backup served %d calls, want 2", got)
+	}
+}
+
+func TestEndpointFailoverAllDown(t *testing.T) {
+	w := dialEndpoints(t, deadEndpoint(t), deadEndpoint(t))
+	if _, err := w.GetBlockNumber(context.Background()); err == nil {
+		t.Fatal("expected error with every endpoint down")
+	}
+}
+
+func TestEndpointFailoverIgnoresRPCErrors(t *testing.T) {
+	primary := newMockRPC(t)
+	primary.handle("eth_blockNumber", func([]json.RawMessage) (interface{}, error) {
+		return nil, &rpcError{code: -32000, msg: "execution reverted"}
+	})
+	backup := newMockRPC(t)
+	backup.result("eth_blockNumber", "0x64")
+
+	w := dialEndpoints(t, primary.server.URL, backup.server.URL)
+	_, err := w.GetBlockNumber(context.Background())
+	if err == nil {
+		t.Fatal("expected the node's error to be returned")
+	}
+	if backup.callCount("eth_blockNumber") != 0 || w.ActiveEndpoint() != primary.server.URL {
+		t.Fatal("failed over on an error returned by a reachable node")
+	}
+}
+
+func TestNewWeb3UtilsWithEndpointsEmpty(t *testing.T) {
+	if _, err := NewWeb3UtilsWithEndpoints(nil); err == nil {
+		t.Fatal("expected error for no endpoints")
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ENSRegistry is the address of the ENS registry on mainnet and its testnets
+var ENSRegistry = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// ENS cache defaults
+const (
+	DefaultENSCacheSize   = 256
+	DefaultENSCacheTTL    = time.Hour
+	DefaultENSNegativeTTL = 5 * time.Minute
+)
+
+// ErrENSNameNotFound is returned for names without a resolver or address
+var ErrENSNameNotFound = errors.New("ens name not found")
+
+var (
+	// resolverSelector is the selector of ENS registry resolver(bytes32)
+	resolverSelector = []byte{0x01, 0x78, 0xb8, 0xbf}
+	// addrSelector is the selector of ENS resolver addr(bytes32)
+	addrSelector = []byte{0x3b, 0x3b, 0x57, 0xde}
+)
+
+// NameHash computes the EIP-137 namehash of an ENS name. Names are only
+// lowercased, not fully UTS-46 normalized, so callers should pass names in
+// their normalized form.
+func NameHash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = crypto.Keccak256Hash(node.Bytes(), crypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+// ResolveENS resolves an ENS name to an address through the ENS registry.
+// Results are cached, including ErrENSNameNotFound for unregistered names,
+// which is kept for a shorter TTL; see WithENSCache.
+func (w *Web3Utils) ResolveENS(ctx context.Context, name string) (common.Address, error) {
+	name = strings.ToLower(name)
+	if addr, err, ok := w.ensCache.get(name); ok {
+		return addr, err
+	}
+
+	addr, err := w.resolveENS(ctx, name)
+	if err == nil || errors.Is(err, ErrENSNameNotFound) {
+		w.ensCache.put(name, addr, err)
+	}
+	return addr, err
+}
+
+// resolveENS looks up the resolver of a name and asks it for the address
+func (w *Web3Utils) resolveENS(ctx context.Context, name string) (common.Address, error) {
+	node := NameHash(name)
+
+	out, err := w.callContract(ctx, ENSRegistry, append(append([]byte{}, resolverSelector...), node.Bytes()...))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to get resolver for %s: %w", name, err)
+	}
+	resolver := common.BytesToAddress(out)
+	if len(out) != 32 || resolver == (common.Address{}) {
+		return common.Address{}, ErrENSNameNotFound
+	}
+
+	out, err = w.callContract(ctx, resolver, append(append([]byte{}, addrSelector...), node.Bytes()...))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to resolve %s: %w", name, err)
+	}
+	addr := common.BytesToAddress(out)
+	if len(out) != 32 || addr == (common.Address{}) {
+		return common.Address{}, ErrENSNameNotFound
+	}
+	return addr, nil
+}
+
+// ResolveENSBatch resolves many ENS names at once. Uncached names are
+// resolved with two Multicall3 calls, one asking the registry for every
+// resolver and one asking the resolvers for every address; where Multicall3
+// is unavailable each name is resolved concurrently on its own. The map holds
+// the names that resolved, keyed exactly as given, and errs[i] is the error of
+// names[i], ErrENSNameNotFound for unregistered names.
+func (w *Web3Utils) ResolveENSBatch(ctx context.Context, names []string) (map[string]common.Address, []error) {
+	addrs := make([]common.Address, len(names))
+	errs := make([]error, len(names))
+
+	var pending []int
+	for i, name := range names {
+		if addr, err, ok := w.ensCache.get(strings.ToLower(name)); ok {
+			addrs[i], errs[i] = addr, err
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	if len(pending) > 0 {
+		lowered := make([]string, len(pending))
+		for j, i := range pending {
+			lowered[j] = strings.ToLower(names[i])
+		}
+		resolved, resolveErrs, err := w.resolveENSMulticall(ctx, lowered)
+		if err != nil {
+			var wg sync.WaitGroup
+			for _, i := range pending {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					addrs[i], errs[i] = w.ResolveENS(ctx, names[i])
+				}(i)
+			}
+			wg.Wait()
+		} else {
+			for j, i := range pending {
+				addrs[i], errs[i] = resolved[j], resolveErrs[j]
+				if errs[i] == nil || errors.Is(errs[i], ErrENSNameNotFound) {
+					w.ensCache.put(lowered[j], addrs[i], errs[i])
+				}
+			}
+		}
+	}
+
+	result := make(map[string]common.Address, len(names))
+	for i, name := range names {
+		if errs[i] == nil {
+			result[name] = addrs[i]
+		}
+	}
+	return result, errs
+}
+
+// resolveENSMulticall resolves normalized names through Multicall3. The
+// returned error is set only if the multicalls themselves failed.
+func (w *Web3Utils) resolveENSMulticall(ctx context.Context, names []string) ([]common.Address, []error, error) {
+	nodes := make([]common.Hash, len(names))
+	calls := make([]multicallCall, len(names))
+	for i, name := range names {
+		nodes[i] = NameHash(name)
+		calls[i] = multicallCall{Target: ENSRegistry, CallData: append(append([]byte{}, resolverSelector...), nodes[i].Bytes()...)}
+	}
+	results, err := w.multicall(ctx, calls)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addrs := make([]common.Address, len(names))
+	errs := make([]error, len(names))
+	var (
+		lookups   []int
+		addrCalls []multicallCall
+	)
+	for i, r := range results {
+		resolver := common.BytesToAddress(r.ReturnData)
+		switch {
+		case !r.Success:
+			errs[i] = fmt.Errorf("failed to get resolver for %s", names[i])
+		case len(r.ReturnData) != 32 || resolver == (common.Address{}):
+			errs[i] = ErrENSNameNotFound
+		default:
+			lookups = append(lookups, i)
+			addrCalls = append(addrCalls, multicallCall{Target: resolver, CallData: append(append([]byte{}, addrSelector...), nodes[i].Bytes()...)})
+		}
+	}
+	if len(addrCalls) == 0 {
+		return addrs, errs, nil
+	}
+
+	results, err = w.multicall(ctx, addrCalls)
+	if err != nil {
+		return nil, nil, err
+	}
+	for j, r := range results {
+		i := lookups[j]
+		addr := common.BytesToAddress(r.ReturnData)
+		switch {
+		case !r.Success:
+			errs[i] = fmt.Errorf("failed to resolve %s", names[i])
+		case len(r.ReturnData) != 32 || addr == (common.Address{}):
+			errs[i] = ErrENSNameNotFound
+		default:
+			addrs[i] = addr
+		}
+	}
+	return addrs, errs, nil
+}
+
+// ensCache is an LRU cache of ENS resolutions with separate TTLs for found
+// and not-found names
+type ensCache struct {
+	size        int
+	ttl         time.Duration
+	negativeTTL time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type ensCacheEntry struct {
+	name    string
+	addr    common.Address
+	err     error
+	expires time.Time
+}
+
+func newENSCache(size int, ttl, negativeTTL time.Duration) *ensCache {
+	return &ensCache{
+		size:        size,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		now:         time.Now,
+		order:       list.New(),
+		entries:     make(map[string]*list.Element),
+	}
+}
+
+// get returns a cached resolution if one exists and has not expired
+func (c *ensCache) get(name string) (common.Address, error, bool) {
+	if c == nil || c.size <= 0 {
+		return common.Address{}, nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[name]
+	if !ok {
+		return common.Address{}, nil, false
+	}
+	entry := el.Value.(*ensCacheEntry)
+	if c.now().After(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, name)
+		return common.Address{}, nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.addr, entry.err, true
+}
+
+// put stores a resolution, evicting the least recently used entry if full
+func (c *ensCache) put(name string, addr common.Address, err error) {
+	if c == nil || c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ttl := c.ttl
+	if err != nil {
+		ttl = c.negativeTTL
+	}
+	entry := &ensCacheEntry{name: name, addr: addr, err: err, expires: c.now().Add(ttl)}
+	if el, ok := c.entries[name]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[name] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*ensCacheEntry).name)
+	}
+}
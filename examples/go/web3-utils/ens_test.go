@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	testResolver = common.HexToAddress("0x231b0Ee14048e9dCcD1d247744d114a4EB5E8E63")
+	vitalikAddr  = common.HexToAddress(testAddress)
+)
+
+// abiWord left-pads an address to a 32-byte ABI word
+func abiWord(addr common.Address) string {
+	return "0x" + strings.Repeat("0", 24) + strings.ToLower(addr.Hex()[2:])
+}
+
+// mockENS serves registry and resolver calls for the given names; names
+// mapped to the zero address have no resolver
+func mockENS(m *mockRPC, names map[string]common.Address) {
+	responses := make(map[string]string)
+	for name, addr := range names {
+		node := NameHash(name).Hex()[2:]
+		if addr == (common.Address{}) {
+			responses["0x0178b8bf"+node] = abiFalse
+			continue
+		}
+		responses["0x0178b8bf"+node] = abiWord(testResolver)
+		responses["0x3b3b57de"+node] = abiWord(addr)
+	}
+	mockContract(m, responses)
+}
+
+func TestNameHash(t *testing.T) {
+	tests := map[string]string{
+		"":        "0x0000000000000000000000000000000000000000000000000000000000000000",
+		"eth":     "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae",
+		"foo.eth": "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+	}
+	for name, want := range tests {
+		if got := NameHash(name).Hex(); got != want {
+			t.Fatalf("NameHash(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestResolveENSCachesResults(t *testing.T) {
+	m := newMockRPC(t)
+	mockENS(m, map[string]common.Address{"vitalik.eth": vitalikAddr})
+	w := m.dial(t)
+
+	for i := 0; i < 2; i++ {
+		addr, err := w.ResolveENS(context.Background(), "vitalik.eth")
+		if err != nil {
+			t.Fatalf("ResolveENS: %v", err)
+		}
+		if addr != vitalikAddr {
+			t.Fatalf("addr = %s, want %s", addr.Hex(), vitalikAddr.Hex())
+		}
+	}
+	if n := m.callCount("eth_call"); n != 2 {
+		t.Fatalf("eth_call made %d times, want 2 (second lookup cached)", n)
+	}
+}
+
+func TestResolveENSNegativeCache(t *testing.T) {
+	m := newMockRPC(t)
+	mockENS(m, map[string]common.Address{"nobody.eth": {}})
+	w := m.dial(t, WithENSCache(10, time.Hour, time.Minute))
+	now := time.Now()
+	w.ensCache.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := w.ResolveENS(context.Background(), "nobody.eth"); !errors.Is(err, ErrENSNameNotFound) {
+			t.Fatalf("err = %v, want ErrENSNameNotFound", err)
+		}
+	}
+	if n := m.callCount("eth_call"); n != 1 {
+		t.Fatalf("eth_call made %d times, want 1 (negative result cached)", n)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := w.ResolveENS(context.Background(), "nobody.eth"); !errors.Is(err, ErrENSNameNotFound) {
+		t.Fatalf("err = %v, want ErrENSNameNotFound", err)
+	}
+	if n := m.callCount("eth_call"); n != 2 {
+		t.Fatalf("eth_call made %d times, want 2 after negative TTL expiry", n)
+	}
+}
+
+func TestENSCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newENSCache(2, time.Hour, time.Minute)
+	c.put("a.eth", common.Address{0x1}, nil)
+	c.put("b.eth", common.Address{0x2}, nil)
+	c.get("a.eth")
+	c.put("c.eth", common.Address{0x3}, nil)
+
+	if _, _, ok := c.get("b.eth"); ok {
+		t.Fatal("least recently used entry not evicted")
+	}
+	if _, _, ok := c.get("a.eth"); !ok {
+		t.Fatal("recently used entry evicted")
+	}
+}
+
+// mockMulticallENS serves the same names as mockENS, additionally answering
+// Multicall3 aggregate3 batches of registry and resolver calls
+func mockMulticallENS(t *testing.T, m *mockRPC, names map[string]common.Address) {
+	responses := make(map[string][]byte)
+	for name, addr := range names {
+		node := NameHash(name).Bytes()
+		if addr == (common.Address{}) {
+			responses[string(append(append([]byte{}, resolverSelector...), node...))] = make([]byte, 32)
+			continue
+		}
+		responses[string(append(append([]byte{}, resolverSelector...), node...))] = common.LeftPadBytes(testResolver.Bytes(), 32)
+		responses[string(append(append([]byte{}, addrSelector...), node...))] = common.LeftPadBytes(addr.Bytes(), 32)
+	}
+	aggregate3 := multicall3.Methods["aggregate3"]
+
+	m.handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		var call struct {
+			To    common.Address `json:"to"`
+			Input hexutil.Bytes  `json:"input"`
+		}
+		if err := json.Unmarshal(params[0], &call); err != nil {
+			return nil, err
+		}
+		if call.To != Multicall3 || !bytes.HasPrefix(call.Input, aggregate3.ID) {
+			t.Errorf("unexpected eth_call to %s", call.To.Hex())
+			return nil, &rpcError{code: 3, msg: "execution reverted"}
+		}
+		args, err := aggregate3.Inputs.Unpack(call.Input[4:])
+		if err != nil {
+			return nil, err
+		}
+		calls := *abi.ConvertType(args[0], new([]multicallCall)).(*[]multicallCall)
+		results := make([]multicallResult, len(calls))
+		for i, c := range calls {
+			out, ok := responses[string(c.CallData)]
+			results[i] = multicallResult{Success: ok, ReturnData: out}
+		}
+		out, err := aggregate3.Outputs.Pack(results)
+		if err != nil {
+			return nil, err
+		}
+		return hexutil.Bytes(out), nil
+	})
+}
+
+func TestResolveENSBatch(t *testing.T) {
+	nickAddr := common.HexToAddress("0xb8c2C29ee19D8307cb7255e1Cd9CbDE883A267d5")
+	names := map[string]common.Address{
+		"vitalik.eth":         vitalikAddr,
+		"nick.eth":            nickAddr,
+		"unregistered123.eth": {},
+	}
+	m := newMockRPC(t)
+	mockMulticallENS(t, m, names)
+	w := m.dial(t)
+
+	query := []string{"vitalik.eth", "unregistered123.eth", "Nick.eth"}
+	addrs, errs := w.ResolveENSBatch(context.Background(), query)
+	if errs[0] != nil || errs[2] != nil {
+		t.Fatalf("errs = %v", errs)
+	}
+	if !errors.Is(errs[1], ErrENSNameNotFound) {
+		t.Fatalf("unregistered name err = %v, want ErrENSNameNotFound", errs[1])
+	}
+	if len(addrs) != 2 || addrs["vitalik.eth"] != vitalikAddr || addrs["Nick.eth"] != nickAddr {
+		t.Fatalf("addrs = %v", addrs)
+	}
+	// One multicall for the resolvers, one for the addresses
+	if n := m.callCount("eth_call"); n != 2 {
+		t.Fatalf("eth_call made %d times, want 2", n)
+	}
+
+	// Results are cached for single lookups
+	if addr, err := w.ResolveENS(context.Background(), "nick.eth"); err != nil || addr != nickAddr {
+		t.Fatalf("ResolveENS = %s, %v", addr.Hex(), err)
+	}
+	if n := m.callCount("eth_call"); n != 2 {
+		t.Fatal("batch results were not cached")
+	}
+}
+
+func TestResolveENSBatchWithoutMulticall(t *testing.T) {
+	m := newMockRPC(t)
+	mockENS(m, map[string]common.Address{"vitalik.eth": vitalikAddr, "unregistered123.eth": {}})
+
+	addrs, errs := m.dial(t).ResolveENSBatch(context.Background(), []string{"vitalik.eth", "unregistered123.eth"})
+	if errs[0] != nil || addrs["vitalik.eth"] != vitalikAddr {
+		t.Fatalf("vitalik.eth = %s, %v", addrs["vitalik.eth"].Hex(), errs[0])
+	}
+	if !errors.Is(errs[1], ErrENSNameNotFound) {
+		t.Fatalf("unregistered name err = %v, want ErrENSNameNotFound", errs[1])
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ENSController is the address of the mainnet .eth registrar controller
+var ENSController = common.HexToAddress("0x253553366Da8546fC250F225fe3d25d0C782303b")
+
+// DefaultENSRegisterGas is the gas assumed for the register step when the
+// node cannot estimate it, e.g. because it does not support state overrides
+// or the name is not available
+const DefaultENSRegisterGas = 270000
+
+// ensEstimateOwner is the placeholder registrant the register step is
+// estimated for; it is funded through a state override
+var ensEstimateOwner = common.HexToAddress("0x000000000000000000000000000000000000E75E")
+
+// ensCommitmentAge is how old the commitment planted for the register
+// estimate is, between the controller's minimum and maximum commitment ages
+const ensCommitmentAge = 10 * time.Minute
+
+// commitmentSlotSearchDepth is how many storage slots commitmentSlot tries
+// as the position of the controller's commitments mapping
+const commitmentSlotSearchDepth = 10
+
+// errCommitmentSlotNotFound is returned when the controller's commitments
+// mapping is not at any of the probed storage positions
+var errCommitmentSlotNotFound = errors.New("ens controller commitments slot not found")
+
+const ensControllerABI = `[
+	{"type":"function","name":"rentPrice","stateMutability":"view","inputs":[{"name":"name","type":"string"},{"name":"duration","type":"uint256"}],"outputs":[{"name":"price","type":"tuple","components":[{"name":"base","type":"uint256"},{"name":"premium","type":"uint256"}]}]},
+	{"type":"function","name":"commit","stateMutability":"nonpayable","inputs":[{"name":"commitment","type":"bytes32"}],"outputs":[]},
+	{"type":"function","name":"commitments","stateMutability":"view","inputs":[{"name":"","type":"bytes32"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"makeCommitment","stateMutability":"pure","inputs":[{"name":"name","type":"string"},{"name":"owner","type":"address"},{"name":"duration","type":"uint256"},{"name":"secret","type":"bytes32"},{"name":"resolver","type":"address"},{"name":"data","type":"bytes[]"},{"name":"reverseRecord","type":"bool"},{"name":"ownerControlledFuses","type":"uint16"}],"outputs":[{"name":"","type":"bytes32"}]},
+	{"type":"function","name":"register","stateMutability":"payable","inputs":[{"name":"name","type":"string"},{"name":"owner","type":"address"},{"name":"duration","type":"uint256"},{"name":"secret","type":"bytes32"},{"name":"resolver","type":"address"},{"name":"data","type":"bytes[]"},{"name":"reverseRecord","type":"bool"},{"name":"ownerControlledFuses","type":"uint16"}],"outputs":[]}
+]`
+
+// ENSRegistrationEstimate is the estimated cost of registering an ENS name
+// through the commit/register flow
+type ENSRegistrationEstimate struct {
+	CommitGas   uint64
+	RegisterGas uint64
+	GasPrice    *big.Int
+	// RentPrice is the registration fee paid to the controller, including
+	// any premium for recently expired names
+	RentPrice *big.Int
+	// GasCost is the gas of both transactions at GasPrice
+	GasCost *big.Int
+	// Total is GasCost plus RentPrice, in Wei
+	Total *big.Int
+}
+
+// EstimateENSRegistrationGas estimates the gas and ETH needed to register a
+// .eth name for duration via ENSController. Registration only succeeds once a
+// matching commitment has matured, so the register step is estimated with
+// state overrides that plant such a commitment and fund the registrant. If
+// the node reverts or cannot estimate it that way, the register step falls
+// back to DefaultENSRegisterGas with a warning to a WarningLogger; other
+// errors, such as cancellation, are returned.
+func (w *Web3Utils) EstimateENSRegistrationGas(ctx context.Context, name string, duration time.Duration) (*ENSRegistrationEstimate, error) {
+	label := strings.TrimSuffix(strings.ToLower(name), ".eth")
+	if label == "" || strings.Contains(label, ".") {
+		return nil, fmt.Errorf("invalid .eth name %q", name)
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("duration must be positive")
+	}
+	controller, err := abi.JSON(strings.NewReader(ensControllerABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse controller ABI: %w", err)
+	}
+	seconds := big.NewInt(int64(duration / time.Second))
+
+	data, err := controller.Pack("rentPrice", label, seconds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rentPrice: %w", err)
+	}
+	out, err := w.callContract(ctx, ENSController, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rent price for %s: %w", name, err)
+	}
+	if len(out) < 64 {
+		return nil, fmt.Errorf("unexpected rentPrice response of %d bytes", len(out))
+	}
+	rent := new(big.Int).SetBytes(out[:32])
+	rent.Add(rent, new(big.Int).SetBytes(out[32:64]))
+
+	secret := crypto.Keccak256Hash([]byte(label), seconds.Bytes())
+	data, err = controller.Pack("commit", secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode commit: %w", err)
+	}
+	commitGas, err := w.EstimateGas(ctx, ethereum.CallMsg{To: &ENSController, Data: data})
+	if err != nil {
+		return nil, err
+	}
+
+	registerGas, err := w.estimateENSRegister(ctx, controller, label, seconds, secret, rent)
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) || errors.Is(err, errCommitmentSlotNotFound) {
+		w.warn("EstimateENSRegistrationGas", fmt.Sprintf("register estimate failed, using DefaultENSRegisterGas %d", DefaultENSRegisterGas), err)
+		registerGas, err = DefaultENSRegisterGas, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrice, err := w.GetGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gasCost := new(big.Int).SetUint64(commitGas + registerGas)
+	gasCost.Mul(gasCost, gasPrice)
+	return &ENSRegistrationEstimate{
+		CommitGas:   commitGas,
+		RegisterGas: registerGas,
+		GasPrice:    gasPrice,
+		RentPrice:   rent,
+		GasCost:     gasCost,
+		Total:       new(big.Int).Add(gasCost, rent),
+	}, nil
+}
+
+// estimateENSRegister estimates the register call for label against state
+// overrides that fund ensEstimateOwner with rent and plant its matured
+// commitment in the controller
+func (w *Web3Utils) estimateENSRegister(ctx context.Context, controller abi.ABI, label string, seconds *big.Int, secret common.Hash, rent *big.Int) (uint64, error) {
+	args := []interface{}{label, ensEstimateOwner, seconds, secret, common.Address{}, [][]byte{}, false, uint16(0)}
+	data, err := controller.Pack("makeCommitment", args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode makeCommitment: %w", err)
+	}
+	out, err := w.callContract(ctx, ENSController, data)
+	if err != nil {
+		return 0, fmt.Errorf("failed to make commitment: %w", err)
+	}
+	commitment := common.BytesToHash(out)
+
+	var head *types.Header
+	err = w.call(ctx, func(c *ethclient.Client) (err error) {
+		head, err = c.HeaderByNumber(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest header: %w", err)
+	}
+	committedAt := common.BigToHash(new(big.Int).SetUint64(head.Time - uint64(ensCommitmentAge/time.Second)))
+	slot, err := w.commitmentSlot(ctx, controller, commitment, committedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err = controller.Pack("register", args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode register: %w", err)
+	}
+	overrides := StateOverride{
+		ENSController:    {StateDiff: map[common.Hash]common.Hash{slot: committedAt}},
+		ensEstimateOwner: {Balance: new(big.Int).Add(rent, big.NewInt(params.Ether))},
+	}
+	msg := ethereum.CallMsg{From: ensEstimateOwner, To: &ENSController, Value: rent, Data: data}
+	var gas hexutil.Uint64
+	err = w.call(ctx, func(c *ethclient.Client) error {
+		ov := map[common.Address]gethclient.OverrideAccount(overrides)
+		return c.Client().CallContext(ctx, &gas, "eth_estimateGas", toCallArg(msg), "latest", ov)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate register: %w", err)
+	}
+	return uint64(gas), nil
+}
+
+// commitmentSlot finds the storage slot of the controller's commitments
+// entry for commitment by overriding the candidate slot of each likely
+// mapping position with value and checking whether commitments(commitment)
+// reflects it
+func (w *Web3Utils) commitmentSlot(ctx context.Context, controller abi.ABI, commitment, value common.Hash) (common.Hash, error) {
+	data, err := controller.Pack("commitments", commitment)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to encode commitments: %w", err)
+	}
+	for position := int64(0); position < commitmentSlotSearchDepth; position++ {
+		slot := crypto.Keccak256Hash(commitment.Bytes(), common.BigToHash(big.NewInt(position)).Bytes())
+		overrides := StateOverride{ENSController: {StateDiff: map[common.Hash]common.Hash{slot: value}}}
+		out, err := w.CallWithOverrides(ctx, ethereum.CallMsg{To: &ENSController, Data: data}, overrides, nil)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to read commitment: %w", err)
+		}
+		if common.BytesToHash(out) == value {
+			return slot, nil
+		}
+	}
+	return common.Hash{}, errCommitmentSlotNotFound
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// mockENSController serves the controller calls EstimateENSRegistrationGas
+// makes, keeping commitments at storage position 1 as the ENS controller
+// does. register estimates are answered by register.
+func mockENSController(t *testing.T, rent *big.Int, register rpcHandler) *mockRPC {
+	t.Helper()
+	selector := func(sig string) []byte { return crypto.Keccak256([]byte(sig))[:4] }
+	commitment := common.HexToHash("0xc0ffee")
+	slot := crypto.Keccak256Hash(commitment.Bytes(), common.BigToHash(big.NewInt(1)).Bytes())
+	decodeInput := func(params []json.RawMessage) []byte {
+		var call struct {
+			Input hexutil.Bytes `json:"input"`
+		}
+		json.Unmarshal(params[0], &call)
+		return call.Input
+	}
+
+	m := newMockRPC(t)
+	m.result("eth_gasPrice", hexutil.Big(*gwei(20)))
+	m.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(100), Time: 1_700_000_000}))
+	m.handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		input := decodeInput(params)
+		switch {
+		case bytes.HasPrefix(input, selector("rentPrice(string,uint256)")):
+			return hexutil.Bytes(append(math.U256Bytes(new(big.Int).Set(rent)), make([]byte, 32)...)), nil
+		case bytes.HasPrefix(input, selector("makeCommitment(string,address,uint256,bytes32,address,bytes[],bool,uint16)")):
+			return commitment, nil
+		case bytes.HasPrefix(input, selector("commitments(bytes32)")):
+			var ov map[common.Address]struct {
+				StateDiff map[common.Hash]common.Hash `json:"stateDiff"`
+			}
+			if len(params) > 2 {
+				json.Unmarshal(params[2], &ov)
+			}
+			return ov[ENSController].StateDiff[slot], nil
+		}
+		return nil, &rpcError{code: 3, msg: "execution reverted"}
+	})
+	m.handle("eth_estimateGas", func(params []json.RawMessage) (interface{}, error) {
+		if bytes.HasPrefix(decodeInput(params), selector("commit(bytes32)")) {
+			return hexutil.Uint64(46000), nil
+		}
+		if len(params) < 3 {
+			return nil, &rpcError{code: 3, msg: "execution reverted"}
+		}
+		var ov map[common.Address]struct {
+			Balance   *hexutil.Big                `json:"balance"`
+			StateDiff map[common.Hash]common.Hash `json:"stateDiff"`
+		}
+		if err := json.Unmarshal(params[2], &ov); err != nil {
+			return nil, err
+		}
+		// The commitment must have matured and the registrant afford the rent
+		committed := ov[ENSController].StateDiff[slot].Big().Uint64()
+		funded := ov[ensEstimateOwner].Balance != nil && ov[ensEstimateOwner].Balance.ToInt().Cmp(rent) >= 0
+		if committed == 0 || committed >= 1_700_000_000-60 || !funded {
+			return nil, &rpcError{code: 3, msg: "execution reverted"}
+		}
+		return register(params)
+	})
+	return m
+}
+
+func TestEstimateENSRegistrationGas(t *testing.T) {
+	// 0.003 ETH base rent, no premium
+	rent := gwei(3000000)
+	m := mockENSController(t, rent, func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(250000), nil
+	})
+	logger := &captureLogger{}
+
+	est, err := m.dial(t, WithLogger(logger)).EstimateENSRegistrationGas(context.Background(), "web3utils.eth", 365*24*time.Hour)
+	if err != nil {
+		t.Fatalf("EstimateENSRegistrationGas: %v", err)
+	}
+	if est.CommitGas != 46000 || est.RegisterGas != 250000 {
+		t.Fatalf("gas = %d + %d, want 46000 + 250000", est.CommitGas, est.RegisterGas)
+	}
+	if len(logger.warnings) != 0 {
+		t.Fatalf("logged warnings %+v, want none", logger.warnings)
+	}
+	if est.RentPrice.Cmp(rent) != 0 {
+		t.Fatalf("rent = %v, want %v", est.RentPrice, rent)
+	}
+	// (46000 + 250000) gas * 20 gwei = 5,920,000 gwei
+	if est.GasCost.Cmp(gwei(5920000)) != 0 {
+		t.Fatalf("gas cost = %v, want %v", est.GasCost, gwei(5920000))
+	}
+	if want := gwei(8920000); est.Total.Cmp(want) != 0 {
+		t.Fatalf("total = %v, want %v", est.Total, want)
+	}
+
+	if _, err := m.dial(t).EstimateENSRegistrationGas(context.Background(), "sub.name.eth", time.Hour); err == nil {
+		t.Fatal("expected error for subdomain")
+	}
+}
+
+func TestEstimateENSRegistrationGasFallback(t *testing.T) {
+	rent := gwei(3000000)
+	// The name is taken, so even a committed registration reverts
+	m := mockENSController(t, rent, func([]json.RawMessage) (interface{}, error) {
+		return nil, &rpcError{code: 3, msg: "execution reverted"}
+	})
+	logger := &captureLogger{}
+
+	est, err := m.dial(t, WithLogger(logger)).EstimateENSRegistrationGas(context.Background(), "taken.eth", 365*24*time.Hour)
+	if err != nil {
+		t.Fatalf("EstimateENSRegistrationGas: %v", err)
+	}
+	if est.RegisterGas != DefaultENSRegisterGas {
+		t.Fatalf("register gas = %d, want fallback %d", est.RegisterGas, DefaultENSRegisterGas)
+	}
+	if len(logger.warnings) != 1 || logger.warnings[0].method != "EstimateENSRegistrationGas" {
+		t.Fatalf("warnings = %+v, want one for the fallback", logger.warnings)
+	}
+}
+
+func TestEstimateENSRegistrationGasCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The caller gives up while the register step is being estimated
+	m := mockENSController(t, gwei(3000000), func([]json.RawMessage) (interface{}, error) {
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+		return hexutil.Uint64(250000), nil
+	})
+
+	if _, err := m.dial(t).EstimateENSRegistrationGas(ctx, "web3utils.eth", 365*24*time.Hour); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled rather than the fallback", err)
+	}
+}
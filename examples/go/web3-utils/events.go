@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DecodedEvent is a contract event log decoded with its ABI
+type DecodedEvent struct {
+	Name        string
+	BlockNumber uint64
+	TxHash      common.Hash
+	LogIndex    uint
+	Args        map[string]interface{}
+}
+
+// EventsBetween fetches and decodes the eventName events emitted by the
+// contract at address between the times from and to, inclusive. The time
+// range is converted to a block range by binary search over block
+// timestamps.
+func (w *Web3Utils) EventsBetween(ctx context.Context, address, abiJSON, eventName string, from, to time.Time) ([]DecodedEvent, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid time range %v - %v", from, to)
+	}
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	event, ok := parsed.Events[eventName]
+	if !ok {
+		return nil, fmt.Errorf("event %s not found in ABI", eventName)
+	}
+
+	head, err := w.GetBlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fromBlock, err := w.firstBlockAtOrAfter(ctx, from, head)
+	if err != nil {
+		return nil, err
+	}
+	toBlock, err := w.lastBlockAtOrBefore(ctx, to, head)
+	if errors.Is(err, ErrBeforeGenesis) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if fromBlock > toBlock {
+		return nil, nil
+	}
+
+	q := ethereum.FilterQuery{
+		Addresses: []common.Address{common.HexToAddress(address)},
+		Topics:    [][]common.Hash{{event.ID}},
+	}
+	logs, err := w.filterLogsChunked(ctx, q, fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	events := make([]DecodedEvent, 0, len(logs))
+	for _, l := range logs {
+		args := make(map[string]interface{})
+		if err := event.Inputs.UnpackIntoMap(args, l.Data); err != nil {
+			return nil, fmt.Errorf("failed to decode %s data in tx %s: %w", eventName, l.TxHash.Hex(), err)
+		}
+		if len(l.Topics) > 0 {
+			if err := abi.ParseTopicsIntoMap(args, indexed, l.Topics[1:]); err != nil {
+				return nil, fmt.Errorf("failed to decode %s topics in tx %s: %w", eventName, l.TxHash.Hex(), err)
+			}
+		}
+		events = append(events, DecodedEvent{
+			Name:        event.Name,
+			BlockNumber: l.BlockNumber,
+			TxHash:      l.TxHash,
+			LogIndex:    l.Index,
+			Args:        args,
+		})
+	}
+	return events, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestEventsBetween(t *testing.T) {
+	const genesis = 1700000000
+	token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	m := newMockRPC(t)
+	mockChainTimes(m, genesis, 1000)
+	m.result("eth_getLogs", []types.Log{{
+		Address:     token,
+		Topics:      []common.Hash{EventTopic("Transfer(address,address,uint256)"), common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:        math.U256Bytes(big.NewInt(5000)),
+		BlockNumber: 150,
+		TxHash:      common.HexToHash(testTxHash),
+		Index:       3,
+	}})
+
+	// Block 101 is the first after the start, block 200 the last before the end
+	start := time.Unix(genesis+100*12+5, 0)
+	end := time.Unix(genesis+200*12+11, 0)
+	events, err := m.dial(t).EventsBetween(context.Background(), token.Hex(), erc20ABI, "Transfer", start, end)
+	if err != nil {
+		t.Fatalf("EventsBetween: %v", err)
+	}
+
+	params := m.callParams("eth_getLogs")
+	if len(params) != 1 {
+		t.Fatalf("eth_getLogs called %d times, want 1", len(params))
+	}
+	var filter struct {
+		FromBlock string `json:"fromBlock"`
+		ToBlock   string `json:"toBlock"`
+	}
+	if err := json.Unmarshal(params[0][0], &filter); err != nil {
+		t.Fatal(err)
+	}
+	if filter.FromBlock != "0x65" || filter.ToBlock != "0xc8" {
+		t.Fatalf("block range = %s-%s, want 0x65-0xc8 (101-200)", filter.FromBlock, filter.ToBlock)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	ev := events[0]
+	if ev.Name != "Transfer" || ev.BlockNumber != 150 || ev.LogIndex != 3 {
+		t.Fatalf("event = %+v", ev)
+	}
+	if ev.Args["from"] != from || ev.Args["to"] != to {
+		t.Fatalf("parties = %v -> %v, want %s -> %s", ev.Args["from"], ev.Args["to"], from.Hex(), to.Hex())
+	}
+	if v, ok := ev.Args["value"].(*big.Int); !ok || v.Int64() != 5000 {
+		t.Fatalf("value = %v, want 5000", ev.Args["value"])
+	}
+}
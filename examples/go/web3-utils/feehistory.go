@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// FeeHistoryAnalysis summarizes EIP-1559 fee data over a window of blocks
+type FeeHistoryAnalysis struct {
+	// OldestBlock is the first block of the window
+	OldestBlock uint64
+	// Blocks is the number of blocks analyzed
+	Blocks int
+	// AverageBaseFee is the mean base fee over the window
+	AverageBaseFee *big.Int
+	// BaseFeeVolatility is the standard deviation of the base fee divided by
+	// its mean (coefficient of variation); 0 means a perfectly flat base fee
+	BaseFeeVolatility float64
+	// AverageGasUsedRatio is the mean fraction of the gas limit used
+	AverageGasUsedRatio float64
+	// TipPercentiles maps each requested percentile to the average priority
+	// fee paid at that percentile across the window
+	TipPercentiles map[float64]*big.Int
+}
+
+// AnalyzeFeeHistory fetches eth_feeHistory for the last blocks blocks with the
+// given reward percentiles and aggregates it into a FeeHistoryAnalysis
+func (w *Web3Utils) AnalyzeFeeHistory(ctx context.Context, blocks int, percentiles []float64) (*FeeHistoryAnalysis, error) {
+	if blocks < 1 {
+		return nil, fmt.Errorf("blocks must be positive, got %d", blocks)
+	}
+
+	var history *ethereum.FeeHistory
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		history, err = c.FeeHistory(ctx, uint64(blocks), nil, percentiles)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee history: %w", err)
+	}
+	return analyzeFeeHistory(history, percentiles)
+}
+
+// analyzeFeeHistory aggregates a fee history response
+func analyzeFeeHistory(history *ethereum.FeeHistory, percentiles []float64) (*FeeHistoryAnalysis, error) {
+	n := len(history.GasUsedRatio)
+	if n == 0 || len(history.BaseFee) < n {
+		return nil, fmt.Errorf("fee history contains no blocks")
+	}
+
+	// BaseFee carries one extra entry for the block after the window.
+	baseFees := history.BaseFee[:n]
+	sum := new(big.Int)
+	for _, fee := range baseFees {
+		sum.Add(sum, fee)
+	}
+	avg := new(big.Int).Div(sum, big.NewInt(int64(n)))
+
+	mean, _ := new(big.Float).SetInt(sum).Float64()
+	mean /= float64(n)
+	var variance, usedRatio float64
+	for i, fee := range baseFees {
+		f, _ := new(big.Float).SetInt(fee).Float64()
+		variance += (f - mean) * (f - mean)
+		usedRatio += history.GasUsedRatio[i]
+	}
+	var volatility float64
+	if mean > 0 {
+		volatility = math.Sqrt(variance/float64(n)) / mean
+	}
+
+	tips := make(map[float64]*big.Int, len(percentiles))
+	for j, p := range percentiles {
+		total, count := new(big.Int), int64(0)
+		for _, rewards := range history.Reward {
+			if j < len(rewards) && rewards[j] != nil {
+				total.Add(total, rewards[j])
+				count++
+			}
+		}
+		if count > 0 {
+			tips[p] = total.Div(total, big.NewInt(count))
+		}
+	}
+
+	return &FeeHistoryAnalysis{
+		OldestBlock:         history.OldestBlock.Uint64(),
+		Blocks:              n,
+		AverageBaseFee:      avg,
+		BaseFeeVolatility:   volatility,
+		AverageGasUsedRatio: usedRatio / float64(n),
+		TipPercentiles:      tips,
+	}, nil
+}
+
+// Reward percentiles GasPriceOracle callers can use for the usual
+// submission speeds
+const (
+	SlowPercentile     = 25
+	StandardPercentile = 50
+	FastPercentile     = 90
+)
+
+// GasPriceOracle recommends priority fees from what recent blocks actually
+// paid: for each of percentiles it returns the average, over the last
+// blockCount blocks, of the tip paid at that percentile of each block, e.g.
+// SlowPercentile, StandardPercentile and FastPercentile. Near genesis the
+// node returns fewer blocks than requested and the average covers those.
+// Percentiles may be given in any order but must be within 0-100.
+func (w *Web3Utils) GasPriceOracle(ctx context.Context, blockCount int, percentiles []float64) (map[float64]*big.Int, error) {
+	if len(percentiles) == 0 {
+		return nil, fmt.Errorf("no percentiles requested")
+	}
+	// eth_feeHistory requires increasing percentiles
+	sorted := make([]float64, 0, len(percentiles))
+	seen := make(map[float64]bool, len(percentiles))
+	for _, p := range percentiles {
+		if p < 0 || p > 100 || math.IsNaN(p) {
+			return nil, fmt.Errorf("percentile %v out of range 0-100", p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			sorted = append(sorted, p)
+		}
+	}
+	sort.Float64s(sorted)
+
+	analysis, err := w.AnalyzeFeeHistory(ctx, blockCount, sorted)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range sorted {
+		if analysis.TipPercentiles[p] == nil {
+			return nil, fmt.Errorf("fee history contains no rewards for percentile %v", p)
+		}
+	}
+	return analysis.TipPercentiles, nil
+}
+
+// inclusionHistoryBlocks is the fee history window InclusionProbability
+// samples
+const inclusionHistoryBlocks = 20
+
+// inclusionPercentiles are the reward percentiles InclusionProbability
+// compares a max fee against
+var inclusionPercentiles = []float64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100}
+
+// InclusionProbability approximates the chance that a transaction paying up
+// to maxFee per gas is included within withinBlocks blocks. For each recent
+// block it takes the highest reward percentile whose base fee plus tip fits
+// under maxFee as that block's inclusion chance, averages those chances, and
+// treats the next withinBlocks blocks as independent tries. It is a rough
+// guide from recent history, not a guarantee.
+func (w *Web3Utils) InclusionProbability(ctx context.Context, maxFee *big.Int, withinBlocks int) (float64, error) {
+	if maxFee == nil || maxFee.Sign() <= 0 {
+		return 0, fmt.Errorf("maxFee must be positive")
+	}
+	if withinBlocks < 1 {
+		return 0, fmt.Errorf("withinBlocks must be positive, got %d", withinBlocks)
+	}
+
+	perBlock, err := w.inclusionChance(ctx, maxFee)
+	if err != nil {
+		return 0, err
+	}
+	return 1 - math.Pow(1-perBlock, float64(withinBlocks)), nil
+}
+
+// ExpectedInclusionTime estimates how long a transaction paying up to maxFee
+// per gas waits to be included. Under the InclusionProbability model the
+// expected number of blocks is the inverse of the per-block chance, which is
+// converted to time with AverageBlockTime. It fails if maxFee is below
+// everything recently included.
+func (w *Web3Utils) ExpectedInclusionTime(ctx context.Context, maxFee *big.Int) (time.Duration, error) {
+	if maxFee == nil || maxFee.Sign() <= 0 {
+		return 0, fmt.Errorf("maxFee must be positive")
+	}
+	perBlock, err := w.inclusionChance(ctx, maxFee)
+	if err != nil {
+		return 0, err
+	}
+	if perBlock == 0 {
+		return 0, fmt.Errorf("max fee %s is below every recently included price", maxFee)
+	}
+	blockTime, err := w.AverageBlockTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(float64(blockTime) / perBlock), nil
+}
+
+// inclusionChance fetches recent fee history and returns the average
+// per-block chance that maxFee is included
+func (w *Web3Utils) inclusionChance(ctx context.Context, maxFee *big.Int) (float64, error) {
+	var history *ethereum.FeeHistory
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		history, err = c.FeeHistory(ctx, inclusionHistoryBlocks, nil, inclusionPercentiles)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get fee history: %w", err)
+	}
+	return blockInclusionChance(history, maxFee, inclusionPercentiles)
+}
+
+// blockInclusionChance averages, over the blocks of a fee history, the
+// fraction of each block's transactions (by percentile) that paid no more
+// than maxFee
+func blockInclusionChance(history *ethereum.FeeHistory, maxFee *big.Int, percentiles []float64) (float64, error) {
+	n := len(history.Reward)
+	if n == 0 || len(history.BaseFee) < n {
+		return 0, fmt.Errorf("fee history contains no blocks")
+	}
+
+	var total float64
+	price := new(big.Int)
+	for i, rewards := range history.Reward {
+		var chance float64
+		for j, p := range percentiles {
+			if j >= len(rewards) || rewards[j] == nil {
+				break
+			}
+			if price.Add(history.BaseFee[i], rewards[j]).Cmp(maxFee) > 0 {
+				break
+			}
+			chance = p / 100
+		}
+		total += chance
+	}
+	return total / float64(n), nil
+}
+
+// OptimalTip recommends a priority fee on a continuous scale between the
+// cheapest and the highest tips recently paid. urgency, from 0 (cheap) to 1
+// (fast), is the desired chance of inclusion within targetBlocks blocks.
+// Following the same model as InclusionProbability, that chance is turned
+// into the per-block percentile it requires, and the tip is interpolated
+// between the average tips paid at the neighboring sampled percentiles. The
+// WithMinTipFloor floor applies.
+func (w *Web3Utils) OptimalTip(ctx context.Context, targetBlocks int, urgency float64) (*big.Int, error) {
+	if targetBlocks < 1 {
+		return nil, fmt.Errorf("targetBlocks must be positive, got %d", targetBlocks)
+	}
+	if urgency < 0 || urgency > 1 || math.IsNaN(urgency) {
+		return nil, fmt.Errorf("urgency must be between 0 and 1, got %v", urgency)
+	}
+
+	analysis, err := w.AnalyzeFeeHistory(ctx, inclusionHistoryBlocks, inclusionPercentiles)
+	if err != nil {
+		return nil, err
+	}
+	// Solve 1-(1-p)^targetBlocks = urgency for the per-block chance p
+	percentile := 100 * (1 - math.Pow(1-urgency, 1/float64(targetBlocks)))
+	tip, err := interpolateTip(analysis.TipPercentiles, inclusionPercentiles, percentile)
+	if err != nil {
+		return nil, err
+	}
+	if minTip := w.currentSettings(ctx).minTip; minTip != nil && tip.Cmp(minTip) < 0 {
+		tip = new(big.Int).Set(minTip)
+	}
+	return tip, nil
+}
+
+// interpolateTip linearly interpolates the tip at percentile from the tips
+// at the sorted sampled percentiles
+func interpolateTip(tips map[float64]*big.Int, percentiles []float64, percentile float64) (*big.Int, error) {
+	var lower float64
+	var lowerTip *big.Int
+	for _, p := range percentiles {
+		tip := tips[p]
+		if tip == nil {
+			continue
+		}
+		if p >= percentile {
+			if lowerTip == nil || p == lower {
+				return new(big.Int).Set(tip), nil
+			}
+			frac := (percentile - lower) / (p - lower)
+			delta := new(big.Float).Mul(new(big.Float).SetInt(new(big.Int).Sub(tip, lowerTip)), big.NewFloat(frac))
+			rounded, _ := delta.Add(delta, big.NewFloat(0.5)).Int(nil)
+			return rounded.Add(rounded, lowerTip), nil
+		}
+		lower, lowerTip = p, tip
+	}
+	if lowerTip == nil {
+		return nil, fmt.Errorf("fee history contains no rewards")
+	}
+	return new(big.Int).Set(lowerTip), nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// mockFeeHistory registers an eth_feeHistory response for four blocks with
+// base fees of 10/20/30/40 gwei and 10th/50th/90th percentile tips
+func mockFeeHistory(m *mockRPC) {
+	m.handle("eth_feeHistory", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{
+			"oldestBlock": "0x64",
+			"baseFeePerGas": []*hexutil.Big{
+				(*hexutil.Big)(gwei(10)), (*hexutil.Big)(gwei(20)), (*hexutil.Big)(gwei(30)),
+				(*hexutil.Big)(gwei(40)), (*hexutil.Big)(gwei(45)),
+			},
+			"gasUsedRatio": []float64{0.25, 0.5, 0.75, 1},
+			"reward": [][]*hexutil.Big{
+				{(*hexutil.Big)(gwei(1)), (*hexutil.Big)(gwei(1)), (*hexutil.Big)(gwei(3))},
+				{(*hexutil.Big)(gwei(1)), (*hexutil.Big)(gwei(2)), (*hexutil.Big)(gwei(4))},
+				{(*hexutil.Big)(gwei(1)), (*hexutil.Big)(gwei(3)), (*hexutil.Big)(gwei(5))},
+				{(*hexutil.Big)(gwei(1)), (*hexutil.Big)(gwei(2)), (*hexutil.Big)(gwei(8))},
+			},
+		}, nil
+	})
+}
+
+func TestAnalyzeFeeHistory(t *testing.T) {
+	m := newMockRPC(t)
+	mockFeeHistory(m)
+
+	a, err := m.dial(t).AnalyzeFeeHistory(context.Background(), 4, []float64{10, 50, 90})
+	if err != nil {
+		t.Fatalf("AnalyzeFeeHistory: %v", err)
+	}
+	if a.OldestBlock != 100 || a.Blocks != 4 {
+		t.Fatalf("window = %d+%d, want 100+4", a.OldestBlock, a.Blocks)
+	}
+	if a.AverageBaseFee.Cmp(gwei(25)) != 0 {
+		t.Fatalf("average base fee = %s, want 25 gwei", a.AverageBaseFee)
+	}
+	// population stddev of 10/20/30/40 is sqrt(125) ~= 11.18, over a mean of 25
+	if want := math.Sqrt(125) / 25; math.Abs(a.BaseFeeVolatility-want) > 1e-9 {
+		t.Fatalf("volatility = %v, want %v", a.BaseFeeVolatility, want)
+	}
+	if a.AverageGasUsedRatio != 0.625 {
+		t.Fatalf("gas used ratio = %v, want 0.625", a.AverageGasUsedRatio)
+	}
+	for p, want := range map[float64]int64{10: 1, 50: 2, 90: 5} {
+		if got := a.TipPercentiles[p]; got == nil || got.Cmp(gwei(want)) != 0 {
+			t.Fatalf("p%v tip = %v, want %d gwei", p, got, want)
+		}
+	}
+}
+
+func TestAnalyzeFeeHistoryRejectsEmptyWindow(t *testing.T) {
+	if _, err := newMockRPC(t).dial(t).AnalyzeFeeHistory(context.Background(), 0, nil); err == nil {
+		t.Fatal("expected error for zero blocks")
+	}
+}
+
+// mockLinearTips registers an eth_feeHistory response for two blocks at a
+// 10 gwei base fee where the pN tip is N/10 gwei
+func mockLinearTips(m *mockRPC) {
+	m.handle("eth_feeHistory", func(params []json.RawMessage) (interface{}, error) {
+		rewards := make([]*hexutil.Big, len(inclusionPercentiles))
+		for i := range rewards {
+			rewards[i] = (*hexutil.Big)(gwei(int64(i)))
+		}
+		return map[string]interface{}{
+			"oldestBlock":   "0x64",
+			"baseFeePerGas": []*hexutil.Big{(*hexutil.Big)(gwei(10)), (*hexutil.Big)(gwei(10)), (*hexutil.Big)(gwei(10))},
+			"gasUsedRatio":  []float64{0.5, 0.5},
+			"reward":        [][]*hexutil.Big{rewards, rewards},
+		}, nil
+	})
+}
+
+func TestInclusionProbability(t *testing.T) {
+	m := newMockRPC(t)
+	mockLinearTips(m)
+	w := m.dial(t)
+
+	tests := []struct {
+		maxFee int64
+		want   float64
+	}{
+		{5, 0},
+		{12, 1 - 0.8*0.8},
+		{15, 1 - 0.5*0.5},
+		{25, 1},
+	}
+	prev := -1.0
+	for _, tt := range tests {
+		got, err := w.InclusionProbability(context.Background(), gwei(tt.maxFee), 2)
+		if err != nil {
+			t.Fatalf("InclusionProbability(%d gwei): %v", tt.maxFee, err)
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Fatalf("InclusionProbability(%d gwei) = %v, want %v", tt.maxFee, got, tt.want)
+		}
+		if got < prev {
+			t.Fatalf("InclusionProbability(%d gwei) = %v, lower than a smaller fee's %v", tt.maxFee, got, prev)
+		}
+		prev = got
+	}
+
+	if _, err := w.InclusionProbability(context.Background(), gwei(15), 0); err == nil {
+		t.Fatal("expected error for zero blocks")
+	}
+}
+
+func TestOptimalTip(t *testing.T) {
+	m := newMockRPC(t)
+	mockLinearTips(m)
+	w := m.dial(t)
+	ctx := context.Background()
+
+	cheap, err := w.OptimalTip(ctx, 1, 0)
+	if err != nil {
+		t.Fatalf("OptimalTip(urgency 0): %v", err)
+	}
+	fast, err := w.OptimalTip(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("OptimalTip(urgency 1): %v", err)
+	}
+	if fast.Cmp(cheap) <= 0 {
+		t.Fatalf("urgency 1 tip %s not above urgency 0 tip %s", fast, cheap)
+	}
+	if cheap.Sign() != 0 || fast.Cmp(gwei(10)) != 0 {
+		t.Fatalf("tips = %s..%s, want 0..10 gwei", cheap, fast)
+	}
+
+	// p45 falls halfway between the p40 and p50 tips
+	mid, err := w.OptimalTip(ctx, 1, 0.45)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := new(big.Int).Div(gwei(9), big.NewInt(2)); mid.Cmp(want) != 0 {
+		t.Fatalf("tip at urgency 0.45 = %s, want %s", mid, want)
+	}
+
+	// Waiting longer needs a smaller per-block chance
+	patient, err := w.OptimalTip(ctx, 5, 0.45)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patient.Cmp(mid) >= 0 {
+		t.Fatalf("tip over 5 blocks %s not below tip over 1 block %s", patient, mid)
+	}
+
+	if _, err := w.OptimalTip(ctx, 1, 1.5); err == nil {
+		t.Fatal("expected error for urgency above 1")
+	}
+	if _, err := w.OptimalTip(ctx, 0, 0.5); err == nil {
+		t.Fatal("expected error for zero target blocks")
+	}
+}
+
+func TestGasPriceOracle(t *testing.T) {
+	// 10 blocks are requested but the chain only has 3 so far
+	m := newMockRPC(t)
+	m.handle("eth_feeHistory", func(params []json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{
+			"oldestBlock": "0x0",
+			"baseFeePerGas": []*hexutil.Big{
+				(*hexutil.Big)(gwei(1)), (*hexutil.Big)(gwei(1)), (*hexutil.Big)(gwei(1)), (*hexutil.Big)(gwei(1)),
+			},
+			"gasUsedRatio": []float64{0.5, 0.5, 0.5},
+			"reward": [][]*hexutil.Big{
+				{(*hexutil.Big)(gwei(1)), (*hexutil.Big)(gwei(2)), (*hexutil.Big)(gwei(6))},
+				{(*hexutil.Big)(gwei(2)), (*hexutil.Big)(gwei(3)), (*hexutil.Big)(gwei(9))},
+				{(*hexutil.Big)(gwei(3)), (*hexutil.Big)(gwei(7)), (*hexutil.Big)(gwei(12))},
+			},
+		}, nil
+	})
+	w := m.dial(t)
+
+	tips, err := w.GasPriceOracle(context.Background(), 10, []float64{FastPercentile, SlowPercentile, StandardPercentile})
+	if err != nil {
+		t.Fatalf("GasPriceOracle: %v", err)
+	}
+	for p, want := range map[float64]int64{SlowPercentile: 2, StandardPercentile: 4, FastPercentile: 9} {
+		if got := tips[p]; got == nil || got.Cmp(gwei(want)) != 0 {
+			t.Fatalf("p%v tip = %v, want %d gwei", p, got, want)
+		}
+	}
+
+	params := m.callParams("eth_feeHistory")[0]
+	var count hexutil.Uint64
+	var sent []float64
+	json.Unmarshal(params[0], &count)
+	json.Unmarshal(params[2], &sent)
+	if count != 10 || len(sent) != 3 || sent[0] != 25 || sent[1] != 50 || sent[2] != 90 {
+		t.Fatalf("requested %d blocks at percentiles %v, want 10 at [25 50 90]", count, sent)
+	}
+
+	if _, err := w.GasPriceOracle(context.Background(), 10, []float64{101}); err == nil {
+		t.Fatal("expected error for percentile above 100")
+	}
+	if _, err := w.GasPriceOracle(context.Background(), 10, nil); err == nil {
+		t.Fatal("expected error for no percentiles")
+	}
+}
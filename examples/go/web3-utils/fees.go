@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// SuggestGasFees suggests EIP-1559 fees for a type-2 transaction: the node's
+// suggested priority fee, raised to the WithMinTipFloor floor if configured,
+// and a max fee of the latest base fee raised by the base fee buffer (100% by
+// default, see WithBaseFeeBuffer) plus that tip. Doubling the base fee keeps
+// the max fee valid through several consecutive full blocks.
+//
+// On chains without EIP-1559 the legacy gas price is returned as
+// maxFeePerGas with a nil maxPriorityFeePerGas, so callers can detect them
+// and send legacy transactions instead.
+func (w *Web3Utils) SuggestGasFees(ctx context.Context) (maxFeePerGas, maxPriorityFeePerGas *big.Int, err error) {
+	header, err := w.latestHeader(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.BaseFee == nil {
+		gasPrice, err := w.GetGasPrice(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gasPrice, nil, nil
+	}
+
+	var tip *big.Int
+	err = w.call(ctx, func(c *ethclient.Client) (err error) {
+		tip, err = c.SuggestGasTipCap(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to suggest gas tip: %w", err)
+	}
+	settings := w.currentSettings(ctx)
+	if settings.minTip != nil && tip.Cmp(settings.minTip) < 0 {
+		tip = new(big.Int).Set(settings.minTip)
+	}
+
+	maxFee := new(big.Int).Mul(header.BaseFee, new(big.Int).SetUint64(100+settings.baseFeeBuffer))
+	maxFee.Quo(maxFee, big.NewInt(100))
+	maxFee.Add(maxFee, tip)
+	return maxFee, tip, nil
+}
+
+// MaxGasForBudget returns the largest gas limit a budget of budgetWei can pay
+// for at the currently suggested max fee per gas
+func (w *Web3Utils) MaxGasForBudget(ctx context.Context, budgetWei *big.Int) (uint64, error) {
+	if budgetWei.Sign() < 0 {
+		return 0, fmt.Errorf("budget must not be negative")
+	}
+	maxFee, _, err := w.SuggestGasFees(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if maxFee.Sign() == 0 {
+		return 0, fmt.Errorf("suggested max fee per gas is zero")
+	}
+
+	gas := new(big.Int).Quo(budgetWei, maxFee)
+	if !gas.IsUint64() {
+		return math.MaxUint64, nil
+	}
+	return gas.Uint64(), nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSuggestGasFees(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(100), BaseFee: gwei(30)}))
+	m.result("eth_maxPriorityFeePerGas", "0x77359400") // 2 gwei
+
+	maxFee, tip, err := m.dial(t).SuggestGasFees(context.Background())
+	if err != nil {
+		t.Fatalf("SuggestGasFees: %v", err)
+	}
+	if tip.Cmp(gwei(2)) != 0 {
+		t.Fatalf("tip = %v, want %v", tip, gwei(2))
+	}
+	if maxFee.Cmp(gwei(62)) != 0 {
+		t.Fatalf("max fee = %v, want %v", maxFee, gwei(62))
+	}
+}
+
+func TestSuggestGasFeesMinTipFloor(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(100), BaseFee: gwei(30)}))
+	m.result("eth_maxPriorityFeePerGas", "0x0")
+
+	maxFee, tip, err := m.dial(t, WithMinTipFloor(gwei(1))).SuggestGasFees(context.Background())
+	if err != nil {
+		t.Fatalf("SuggestGasFees: %v", err)
+	}
+	if tip.Cmp(gwei(1)) != 0 {
+		t.Fatalf("tip = %v, want floor %v", tip, gwei(1))
+	}
+	if maxFee.Cmp(gwei(61)) != 0 {
+		t.Fatalf("max fee = %v, want %v", maxFee, gwei(61))
+	}
+}
+
+func TestMaxGasForBudget(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(100), BaseFee: gwei(24)}))
+	m.result("eth_maxPriorityFeePerGas", "0x77359400") // 2 gwei
+
+	// 50 gwei max fee: a budget of 1,050,000 gwei plus change buys 21,000 gas
+	budget := new(big.Int).Add(gwei(1050000), gwei(49))
+	gas, err := m.dial(t).MaxGasForBudget(context.Background(), budget)
+	if err != nil {
+		t.Fatalf("MaxGasForBudget: %v", err)
+	}
+	if gas != 21000 {
+		t.Fatalf("gas = %d, want 21000", gas)
+	}
+}
+
+func TestSuggestGasFeesLegacyChain(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(100)}))
+	m.result("eth_gasPrice", "0x4a817c800") // 20 gwei
+
+	maxFee, tip, err := m.dial(t).SuggestGasFees(context.Background())
+	if err != nil {
+		t.Fatalf("SuggestGasFees: %v", err)
+	}
+	if tip != nil {
+		t.Fatalf("tip = %v, want nil on a pre-London chain", tip)
+	}
+	if maxFee.Cmp(gwei(20)) != 0 {
+		t.Fatalf("max fee = %v, want legacy gas price %v", maxFee, gwei(20))
+	}
+	if n := m.callCount("eth_maxPriorityFeePerGas"); n != 0 {
+		t.Fatalf("eth_maxPriorityFeePerGas called %d times on a legacy chain", n)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// feeScanChunkSize is how many blocks TotalFeesPaid scans before fetching
+// the receipts of the transactions found in them in one batch request
+const feeScanChunkSize = 100
+
+// TotalFeesPaid sums the gas fees address paid for the transactions it sent
+// in blocks fromBlock to toBlock: each transaction's gas used times its
+// effective gas price, taken from its receipt. Reverted transactions are
+// included since their gas is paid all the same. Every block in the range is
+// fetched, so keep the range small; blocks and then the receipts found in
+// them are fetched in batches per feeScanChunkSize blocks.
+func (w *Web3Utils) TotalFeesPaid(ctx context.Context, address string, fromBlock, toBlock uint64) (*big.Int, error) {
+	if fromBlock > toBlock {
+		return nil, fmt.Errorf("invalid block range %d-%d", fromBlock, toBlock)
+	}
+	account := common.HexToAddress(address)
+
+	total := new(big.Int)
+	for start := fromBlock; start <= toBlock; start += feeScanChunkSize {
+		end := start + feeScanChunkSize - 1
+		if end > toBlock || end < start {
+			end = toBlock
+		}
+		blocks, err := w.blockTransactions(ctx, start, end)
+		if err != nil {
+			return nil, err
+		}
+		var sent []*types.Transaction
+		for _, txs := range blocks {
+			for _, tx := range txs {
+				if sender, err := txSender(tx); err == nil && sender == account {
+					sent = append(sent, tx)
+				}
+			}
+		}
+
+		fees, err := w.transactionFees(ctx, sent)
+		if err != nil {
+			return nil, err
+		}
+		total.Add(total, fees)
+		if end == toBlock {
+			break
+		}
+	}
+	return total, nil
+}
+
+// transactionFees fetches the receipts of txs in one batch request and sums
+// the fees they paid. Receipts without an effective gas price, from nodes
+// predating it, fall back to the transaction's gas price.
+func (w *Web3Utils) transactionFees(ctx context.Context, txs []*types.Transaction) (*big.Int, error) {
+	total := new(big.Int)
+	if len(txs) == 0 {
+		return total, nil
+	}
+	receipts := make([]*types.Receipt, len(txs))
+	batch := make([]rpc.BatchElem, len(txs))
+	for i, tx := range txs {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{tx.Hash()},
+			Result: &receipts[i],
+		}
+	}
+	err := w.call(ctx, func(c *ethclient.Client) error {
+		return c.Client().BatchCallContext(ctx, batch)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipts: %w", err)
+	}
+
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("failed to get receipt of %s: %w", txs[i].Hash().Hex(), elem.Error)
+		}
+		receipt := receipts[i]
+		if receipt == nil {
+			return nil, fmt.Errorf("missing receipt for tx %s", txs[i].Hash().Hex())
+		}
+		price := receipt.EffectiveGasPrice
+		if price == nil {
+			price = txs[i].GasPrice()
+		}
+		total.Add(total, new(big.Int).Mul(price, new(big.Int).SetUint64(receipt.GasUsed)))
+	}
+	return total, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestTotalFeesPaid(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress(testAddress)
+	sign := func(nonce uint64) *types.Transaction {
+		tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+			ChainID: big.NewInt(1), Nonce: nonce, GasTipCap: gwei(2), GasFeeCap: gwei(50), Gas: 100000, To: &to,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	first, second := sign(0), sign(1)
+	incoming := legacyTx(t, 0, account, eth(1), gwei(10))
+	blocks := map[uint64][]*types.Transaction{
+		10: {first},
+		11: {incoming},
+		12: {second},
+	}
+	fees := map[common.Hash]struct {
+		gasUsed uint64
+		price   *big.Int
+	}{
+		first.Hash():    {21000, gwei(12)},
+		second.Hash():   {50000, gwei(20)},
+		incoming.Hash(): {21000, gwei(10)},
+	}
+
+	m := newMockRPC(t)
+	m.handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		n, _ := blockTag(params[0])
+		return mockBlock(&types.Header{Number: new(big.Int).SetUint64(n), BaseFee: gwei(10)}, blocks[n]...), nil
+	})
+	m.handle("eth_getTransactionReceipt", func(params []json.RawMessage) (interface{}, error) {
+		var hash common.Hash
+		json.Unmarshal(params[0], &hash)
+		r := mockReceipt(hash.Hex(), 10, types.ReceiptStatusSuccessful)
+		r.GasUsed, r.EffectiveGasPrice = fees[hash].gasUsed, fees[hash].price
+		return r, nil
+	})
+
+	total, err := m.dial(t).TotalFeesPaid(context.Background(), account.Hex(), 10, 12)
+	if err != nil {
+		t.Fatalf("TotalFeesPaid: %v", err)
+	}
+	// 21000*12 + 50000*20 gwei; the incoming transfer is not ours to pay
+	want := new(big.Int).Add(new(big.Int).Mul(gwei(12), big.NewInt(21000)), new(big.Int).Mul(gwei(20), big.NewInt(50000)))
+	if total.Cmp(want) != 0 {
+		t.Fatalf("total fees = %s, want %s", total, want)
+	}
+	// One batch for the 3 blocks, then one for the 2 receipts
+	if sizes := m.batchSizes(); len(sizes) != 2 || sizes[0] != 3 || sizes[1] != 2 {
+		t.Fatalf("batches = %v, want [3 2]", sizes)
+	}
+}
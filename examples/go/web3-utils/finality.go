@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// GetFinalizedBlock returns the header of the latest finalized block, which
+// can no longer be reorganized away
+func (w *Web3Utils) GetFinalizedBlock(ctx context.Context) (*types.Header, error) {
+	var header *types.Header
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		header, err = c.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get finalized block: %w", err)
+	}
+	return header, nil
+}
+
+// SubscribeFinalized polls the finalized block every interval and emits its
+// header each time it advances, so indexers know which data is reorg-safe.
+// The channel is closed when ctx is cancelled or on Shutdown. Polling
+// failures are skipped and retried on the next tick.
+func (w *Web3Utils) SubscribeFinalized(ctx context.Context, interval time.Duration) <-chan *types.Header {
+	out := make(chan *types.Header)
+	ctx, done, err := w.watch(ctx)
+	if err != nil {
+		close(out)
+		return out
+	}
+	go func() {
+		defer done()
+		defer close(out)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var last *big.Int
+		for {
+			header, err := w.GetFinalizedBlock(ctx)
+			if err == nil && (last == nil || header.Number.Cmp(last) > 0) {
+				last = header.Number
+				select {
+				case out <- header:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSubscribeFinalized(t *testing.T) {
+	// Finalized block number returned by successive polls
+	script := []int64{100, 100, 101, 101, 101, 103, 103}
+	var step atomic.Int64
+	m := newMockRPC(t)
+	m.handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		var tag string
+		json.Unmarshal(params[0], &tag)
+		if tag != "finalized" {
+			t.Errorf("block tag = %q, want finalized", tag)
+		}
+		i := step.Add(1) - 1
+		if i >= int64(len(script)) {
+			i = int64(len(script)) - 1
+		}
+		return mockBlock(&types.Header{Number: big.NewInt(script[i])}), nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got []int64
+	for header := range m.dial(t).SubscribeFinalized(ctx, time.Millisecond) {
+		got = append(got, header.Number.Int64())
+		if len(got) == 3 {
+			// Give the watcher a few more unchanged polls before stopping
+			for step.Load() < int64(len(script))+3 {
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+		}
+	}
+
+	want := []int64{100, 101, 103}
+	if len(got) != len(want) {
+		t.Fatalf("emitted %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("emitted %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ErrNoBaseFee is returned on chains that have not activated EIP-1559
+var ErrNoBaseFee = errors.New("chain does not support EIP-1559 base fee")
+
+// ErrNoBlobGas is returned on chains that have not activated EIP-4844 blobs
+var ErrNoBlobGas = errors.New("chain does not support EIP-4844 blob gas")
+
+// EstimateGas estimates the gas limit needed to execute msg. If the call
+// reverts, the error wraps a *RevertError carrying the decoded reason. If the
+// node fails to produce an estimate for another reason and
+// WithGasEstimateFallback is configured, the fallback limit is returned
+// instead of an error and the underlying error is reported to the Logger as
+// a warning if it implements WarningLogger. Cancellation and Shutdown are
+// always returned as errors.
+func (w *Web3Utils) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	var gas uint64
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		gas, err = c.EstimateGas(ctx, msg)
+		return err
+	})
+	if err != nil {
+		if revert := asRevertError(err); revert != nil {
+			return 0, fmt.Errorf("failed to estimate gas: %w", revert)
+		}
+		// A cancelled or shut-down caller must not go on with a made-up limit
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrShutdown) {
+			return 0, fmt.Errorf("failed to estimate gas: %w", err)
+		}
+		if w.gasFallback > 0 {
+			w.warn("EstimateGas", fmt.Sprintf("gas estimate failed, using fallback limit %d", w.gasFallback), err)
+			return w.gasFallback, nil
+		}
+		return 0, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	return gas, nil
+}
+
+// EstimateGasWithBuffer returns the EstimateGas result raised by
+// bufferPercent, e.g. 20 for a 20% safety margin
+func (w *Web3Utils) EstimateGasWithBuffer(ctx context.Context, msg ethereum.CallMsg, bufferPercent int) (uint64, error) {
+	if bufferPercent < 0 {
+		return 0, fmt.Errorf("bufferPercent must not be negative, got %d", bufferPercent)
+	}
+	gas, err := w.EstimateGas(ctx, msg)
+	if err != nil {
+		return 0, err
+	}
+	return gas + gas*uint64(bufferPercent)/100, nil
+}
+
+// EffectiveGasPrice returns the price per gas a transaction pays in a block
+// with the given base fee: baseFee plus the tip it can afford, or the plain
+// gas price for pre-London blocks (nil baseFee)
+func EffectiveGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return new(big.Int).Set(tx.GasPrice())
+	}
+	tip, err := tx.EffectiveGasTip(baseFee)
+	if err != nil {
+		// Fee cap below the base fee: the tx could not have been included,
+		// so it pays at most its cap.
+		return new(big.Int).Set(tx.GasFeeCap())
+	}
+	return tip.Add(tip, baseFee)
+}
+
+// recentBlocks fetches the last n blocks, oldest first
+func (w *Web3Utils) recentBlocks(ctx context.Context, n int) ([]*types.Block, error) {
+	head, err := w.GetBlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(n) > head+1 {
+		n = int(head + 1)
+	}
+
+	blocks := make([]*types.Block, 0, n)
+	for num := head + 1 - uint64(n); num <= head; num++ {
+		var block *types.Block
+		err := w.call(ctx, func(c *ethclient.Client) (err error) {
+			block, err = c.BlockByNumber(ctx, new(big.Int).SetUint64(num))
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block %d: %w", num, err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// GasPricePercentileRank reports where price sits, from 0 to 100, in the
+// distribution of effective gas prices paid over the last blocks blocks.
+// Prices equal to price count as half below, so a price matching every
+// transaction ranks at the 50th percentile.
+func (w *Web3Utils) GasPricePercentileRank(ctx context.Context, price *big.Int, blocks int) (float64, error) {
+	if blocks < 1 {
+		return 0, fmt.Errorf("blocks must be positive, got %d", blocks)
+	}
+	recent, err := w.recentBlocks(ctx, blocks)
+	if err != nil {
+		return 0, err
+	}
+
+	var below, equal, total float64
+	for _, block := range recent {
+		for _, tx := range block.Transactions() {
+			switch EffectiveGasPrice(tx, block.BaseFee()).Cmp(price) {
+			case -1:
+				below++
+			case 0:
+				equal++
+			}
+			total++
+		}
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("no transactions in the last %d blocks", blocks)
+	}
+	return (below + equal/2) / total * 100, nil
+}
+
+// ProjectRecurringCost estimates the cost of sending a transaction using
+// gasLimit timesPerDay times a day for days days at the current gas price.
+// The total is returned both in Wei and in ETH.
+func (w *Web3Utils) ProjectRecurringCost(ctx context.Context, gasLimit uint64, timesPerDay int, days int) (*big.Int, *big.Float, error) {
+	if timesPerDay < 0 || days < 0 {
+		return nil, nil, fmt.Errorf("frequency must not be negative")
+	}
+	gasPrice, err := w.GetGasPrice(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total := new(big.Int).SetUint64(gasLimit)
+	total.Mul(total, gasPrice)
+	total.Mul(total, big.NewInt(int64(timesPerDay)))
+	total.Mul(total, big.NewInt(int64(days)))
+	return total, WeiToEth(total), nil
+}
+
+// BaseFee returns the base fee per gas of the latest block. It fetches only
+// the header, so it is cheaper than SuggestGasFees when the tip is not needed.
+func (w *Web3Utils) BaseFee(ctx context.Context) (*big.Int, error) {
+	header, err := w.latestHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if header.BaseFee == nil {
+		return nil, ErrNoBaseFee
+	}
+	return header.BaseFee, nil
+}
+
+// BlobBaseFee returns the current price per unit of blob gas (EIP-4844),
+// derived from the latest header's excess blob gas
+func (w *Web3Utils) BlobBaseFee(ctx context.Context) (*big.Int, error) {
+	header, err := w.latestHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if header.ExcessBlobGas == nil {
+		return nil, ErrNoBlobGas
+	}
+	return eip4844.CalcBlobFee(*header.ExcessBlobGas), nil
+}
+
+// CalldataGasCost returns the intrinsic gas charged for data as transaction
+// calldata: 16 per non-zero byte and 4 per zero byte (EIP-2028). On rollups
+// this dominates the L1 data cost of a transaction.
+func CalldataGasCost(data []byte) uint64 {
+	var gas uint64
+	for _, b := range data {
+		if b == 0 {
+			gas += params.TxDataZeroGas
+		} else {
+			gas += params.TxDataNonZeroGasEIP2028
+		}
+	}
+	return gas
+}
+
+// ZeroByteRatio returns the fraction of zero bytes in data, from 0 to 1. The
+// higher the ratio, the cheaper the calldata per byte.
+func ZeroByteRatio(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	zeros := 0
+	for _, b := range data {
+		if b == 0 {
+			zeros++
+		}
+	}
+	return float64(zeros) / float64(len(data))
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestEstimateGas(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_estimateGas", "0x5208")
+
+	to := common.HexToAddress(testAddress)
+	gas, err := m.dial(t).EstimateGas(context.Background(), ethereum.CallMsg{To: &to})
+	if err != nil {
+		t.Fatalf("EstimateGas: %v", err)
+	}
+	if gas != 21000 {
+		t.Fatalf("gas = %d, want 21000", gas)
+	}
+}
+
+func TestEstimateGasFallback(t *testing.T) {
+	m := newMockRPC(t)
+	m.handle("eth_estimateGas", func([]json.RawMessage) (interface{}, error) {
+		return nil, errors.New("state-dependent call not supported")
+	})
+
+	to := common.HexToAddress(testAddress)
+	msg := ethereum.CallMsg{To: &to, Data: []byte{0x01}}
+	if _, err := m.dial(t).EstimateGas(context.Background(), msg); err == nil {
+		t.Fatal("expected error without fallback")
+	}
+
+	logger := &captureLogger{}
+	gas, err := m.dial(t, WithGasEstimateFallback(250000), WithLogger(logger)).EstimateGas(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("EstimateGas with fallback: %v", err)
+	}
+	if gas != 250000 {
+		t.Fatalf("gas = %d, want fallback 250000", gas)
+	}
+	if len(logger.warnings) != 1 {
+		t.Fatalf("logged %d warnings, want 1", len(logger.warnings))
+	}
+	warning := logger.warnings[0]
+	if warning.method != "EstimateGas" || !strings.Contains(warning.msg, "fallback limit 250000") ||
+		warning.err == nil || !strings.Contains(warning.err.Error(), "state-dependent call not supported") {
+		t.Fatalf("warning = %+v, want the fallback and its cause", warning)
+	}
+}
+
+func TestEstimateGasFallbackKeepsCancellation(t *testing.T) {
+	m := newMockRPC(t)
+	m.handle("eth_estimateGas", func([]json.RawMessage) (interface{}, error) {
+		return nil, errors.New("state-dependent call not supported")
+	})
+	to := common.HexToAddress(testAddress)
+	msg := ethereum.CallMsg{To: &to}
+	w := m.dial(t, WithGasEstimateFallback(250000))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if gas, err := w.EstimateGas(ctx, msg); !errors.Is(err, context.Canceled) {
+		t.Fatalf("EstimateGas(cancelled) = %d, %v; want context.Canceled", gas, err)
+	}
+
+	if err := w.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if gas, err := w.EstimateGas(context.Background(), msg); !errors.Is(err, ErrShutdown) {
+		t.Fatalf("EstimateGas(after Shutdown) = %d, %v; want ErrShutdown", gas, err)
+	}
+}
+
+func TestEstimateGasRevertReason(t *testing.T) {
+	m := newMockRPC(t)
+	m.handle("eth_estimateGas", func([]json.RawMessage) (interface{}, error) {
+		return nil, &rpcError{code: 3, msg: "execution reverted", data: "0x08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"000000000000000000000000000000000000000000000000000000000000001a" +
+			"4e6f7420656e6f7567682045746865722070726f76696465642e000000000000"}
+	})
+
+	to := common.HexToAddress(testAddress)
+	// A revert is not papered over by the fallback limit
+	_, err := m.dial(t, WithGasEstimateFallback(250000)).EstimateGas(context.Background(), ethereum.CallMsg{To: &to})
+	var revert *RevertError
+	if !errors.As(err, &revert) {
+		t.Fatalf("err = %v, want *RevertError", err)
+	}
+	if revert.Reason != "Not enough Ether provided." {
+		t.Fatalf("reason = %q, want %q", revert.Reason, "Not enough Ether provided.")
+	}
+	if want := "failed to estimate gas: execution reverted: Not enough Ether provided."; err.Error() != want {
+		t.Fatalf("err = %q, want %q", err, want)
+	}
+}
+
+func TestEstimateGasDatalessRevert(t *testing.T) {
+	tests := map[string]*rpcError{
+		"no data":    {code: 3, msg: "execution reverted"},
+		"empty data": {code: 3, msg: "execution reverted", data: "0x"},
+		"message":    {code: -32000, msg: "execution reverted"},
+	}
+	for name, rpcErr := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newMockRPC(t)
+			m.handle("eth_estimateGas", func([]json.RawMessage) (interface{}, error) {
+				return nil, rpcErr
+			})
+
+			to := common.HexToAddress(testAddress)
+			gas, err := m.dial(t, WithGasEstimateFallback(250000)).EstimateGas(context.Background(), ethereum.CallMsg{To: &to})
+			var revert *RevertError
+			if !errors.As(err, &revert) {
+				t.Fatalf("EstimateGas = %d, %v; want *RevertError, not the fallback", gas, err)
+			}
+			if revert.Reason != "" {
+				t.Fatalf("reason = %q, want empty", revert.Reason)
+			}
+			if want := "failed to estimate gas: execution reverted"; err.Error() != want {
+				t.Fatalf("err = %q, want %q", err, want)
+			}
+		})
+	}
+}
+
+func TestEstimateGasWithBuffer(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_estimateGas", "0x5208")
+
+	to := common.HexToAddress(testAddress)
+	gas, err := m.dial(t).EstimateGasWithBuffer(context.Background(), ethereum.CallMsg{To: &to}, 20)
+	if err != nil {
+		t.Fatalf("EstimateGasWithBuffer: %v", err)
+	}
+	if gas != 25200 {
+		t.Fatalf("gas = %d, want 25200", gas)
+	}
+}
+
+// legacyTx signs a legacy transaction paying gasPrice with a throwaway key
+func legacyTx(t *testing.T, nonce uint64, to common.Address, value, gasPrice *big.Int) *types.Transaction {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := types.SignNewTx(key, types.HomesteadSigner{}, &types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      21000,
+		To:       &to,
+		Value:    value,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestEffectiveGasPrice(t *testing.T) {
+	to := common.HexToAddress(testAddress)
+	tests := []struct {
+		name    string
+		tx      *types.Transaction
+		baseFee *big.Int
+		want    *big.Int
+	}{
+		{"legacy pre-london", legacyTx(t, 0, to, nil, gwei(30)), nil, gwei(30)},
+		{"legacy post-london", legacyTx(t, 0, to, nil, gwei(30)), gwei(20), gwei(30)},
+		{"dynamic full tip", dynamicTx(t, 0, to, gwei(2), gwei(50)), gwei(20), gwei(22)},
+		{"dynamic capped tip", dynamicTx(t, 0, to, gwei(5), gwei(23)), gwei(20), gwei(23)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EffectiveGasPrice(tt.tx, tt.baseFee); got.Cmp(tt.want) != 0 {
+				t.Fatalf("effective price = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGasPricePercentileRank(t *testing.T) {
+	to := common.HexToAddress(testAddress)
+	blocks := map[uint64][]*types.Transaction{
+		9:  {legacyTx(t, 0, to, nil, gwei(10)), legacyTx(t, 0, to, nil, gwei(20))},
+		10: {legacyTx(t, 0, to, nil, gwei(30)), legacyTx(t, 0, to, nil, gwei(40))},
+	}
+
+	m := newMockRPC(t)
+	m.result("eth_blockNumber", "0xa")
+	m.handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		num, _ := blockTag(params[0])
+		return mockBlock(&types.Header{Number: new(big.Int).SetUint64(num)}, blocks[num]...), nil
+	})
+	w := m.dial(t)
+
+	for _, tt := range []struct {
+		price *big.Int
+		want  float64
+	}{
+		{gwei(5), 0},
+		{gwei(25), 50},
+		{gwei(30), 62.5},
+		{gwei(50), 100},
+	} {
+		rank, err := w.GasPricePercentileRank(context.Background(), tt.price, 2)
+		if err != nil {
+			t.Fatalf("GasPricePercentileRank: %v", err)
+		}
+		if rank != tt.want {
+			t.Fatalf("rank of %s = %v, want %v", tt.price, rank, tt.want)
+		}
+	}
+	if n := m.callCount("eth_getBlockByNumber"); n != 8 {
+		t.Fatalf("eth_getBlockByNumber called %d times, want 8", n)
+	}
+}
+
+func TestProjectRecurringCost(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_gasPrice", "0x4a817c800") // 20 gwei
+
+	wei, eth, err := m.dial(t).ProjectRecurringCost(context.Background(), 150000, 24, 7)
+	if err != nil {
+		t.Fatalf("ProjectRecurringCost: %v", err)
+	}
+	// 150000 gas * 20 gwei * 24 tx/day * 7 days = 0.504 ETH
+	want, _ := new(big.Int).SetString("504000000000000000", 10)
+	if wei.Cmp(want) != 0 {
+		t.Fatalf("cost = %s wei, want %s", wei, want)
+	}
+	if got := eth.Text('f', 3); got != "0.504" {
+		t.Fatalf("cost = %s ETH, want 0.504", got)
+	}
+
+	if _, _, err := m.dial(t).ProjectRecurringCost(context.Background(), 21000, -1, 7); err == nil {
+		t.Fatal("expected error for negative frequency")
+	}
+}
+
+func TestBaseFee(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(100), BaseFee: gwei(23)}))
+
+	baseFee, err := m.dial(t).BaseFee(context.Background())
+	if err != nil {
+		t.Fatalf("BaseFee: %v", err)
+	}
+	if baseFee.Cmp(gwei(23)) != 0 {
+		t.Fatalf("base fee = %v, want %v", baseFee, gwei(23))
+	}
+
+	legacy := newMockRPC(t)
+	legacy.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(100)}))
+	if _, err := legacy.dial(t).BaseFee(context.Background()); !errors.Is(err, ErrNoBaseFee) {
+		t.Fatalf("err = %v, want ErrNoBaseFee", err)
+	}
+}
+
+func TestBlobBaseFee(t *testing.T) {
+	// e^10 times the 1 wei minimum: ten update fractions of excess blob gas
+	excess := uint64(10 * 3338477)
+	m := newMockRPC(t)
+	m.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(100), BaseFee: gwei(1), ExcessBlobGas: &excess, BlobGasUsed: new(uint64)}))
+
+	fee, err := m.dial(t).BlobBaseFee(context.Background())
+	if err != nil {
+		t.Fatalf("BlobBaseFee: %v", err)
+	}
+	if fee.Int64() != 22026 {
+		t.Fatalf("blob base fee = %s, want 22026", fee)
+	}
+
+	preDencun := newMockRPC(t)
+	preDencun.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(100), BaseFee: gwei(1)}))
+	if _, err := preDencun.dial(t).BlobBaseFee(context.Background()); !errors.Is(err, ErrNoBlobGas) {
+		t.Fatalf("err = %v, want ErrNoBlobGas", err)
+	}
+}
+
+func TestCalldataGasCost(t *testing.T) {
+	tests := []struct {
+		data  []byte
+		gas   uint64
+		ratio float64
+	}{
+		{nil, 0, 0},
+		{[]byte{0, 0, 0, 0}, 16, 1},
+		{[]byte{1, 2, 3, 4}, 64, 0},
+		// transfer(address,uint256) selector followed by a zero-padded word
+		{append([]byte{0xa9, 0x05, 0x9c, 0xbb}, append(make([]byte, 31), 0x01)...), 4*16 + 31*4 + 16, 31.0 / 36},
+	}
+	for _, tt := range tests {
+		if got := CalldataGasCost(tt.data); got != tt.gas {
+			t.Errorf("CalldataGasCost(%x) = %d, want %d", tt.data, got, tt.gas)
+		}
+		if got := ZeroByteRatio(tt.data); got != tt.ratio {
+			t.Errorf("ZeroByteRatio(%x) = %v, want %v", tt.data, got, tt.ratio)
+		}
+	}
+}
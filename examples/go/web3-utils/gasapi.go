@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+)
+
+// Fees holds gas prices in Wei for three confirmation speeds
+type Fees struct {
+	Slow     *big.Int
+	Standard *big.Int
+	Fast     *big.Int
+}
+
+// GasAPIFields maps the slow, standard and fast prices onto fields of a gas
+// API's JSON response. Nested fields are addressed with dot-separated paths,
+// e.g. "result.SafeGasPrice".
+type GasAPIFields struct {
+	Slow     string
+	Standard string
+	Fast     string
+}
+
+var (
+	// DefaultGasAPIFields reads top-level slow, standard and fast fields
+	DefaultGasAPIFields = GasAPIFields{Slow: "slow", Standard: "standard", Fast: "fast"}
+	// EtherscanGasAPIFields reads the response of Etherscan's gas oracle
+	EtherscanGasAPIFields = GasAPIFields{
+		Slow:     "result.SafeGasPrice",
+		Standard: "result.ProposeGasPrice",
+		Fast:     "result.FastGasPrice",
+	}
+)
+
+// GasAPIOracle fetches gas prices from an external HTTP gas API that reports
+// them in Gwei. It is a fallback for when the node's own fee data is
+// unreliable.
+type GasAPIOracle struct {
+	URL    string
+	Fields GasAPIFields
+	Client *http.Client
+}
+
+// NewGasAPIOracle creates an oracle for the API at url using the given field
+// mapping
+func NewGasAPIOracle(url string, fields GasAPIFields) *GasAPIOracle {
+	return &GasAPIOracle{URL: url, Fields: fields, Client: http.DefaultClient}
+}
+
+// Fees fetches the current slow, standard and fast gas prices
+func (o *GasAPIOracle) Fees(ctx context.Context) (*Fees, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gas api request: %w", err)
+	}
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query gas api: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gas api returned status %s", resp.Status)
+	}
+
+	var body interface{}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode gas api response: %w", err)
+	}
+
+	fees := new(Fees)
+	for _, f := range []struct {
+		path string
+		dst  **big.Int
+	}{
+		{o.Fields.Slow, &fees.Slow},
+		{o.Fields.Standard, &fees.Standard},
+		{o.Fields.Fast, &fees.Fast},
+	} {
+		if *f.dst, err = gweiField(body, f.path); err != nil {
+			return nil, err
+		}
+	}
+	return fees, nil
+}
+
+// gweiField looks up the dot-separated path in a decoded JSON document and
+// converts the Gwei amount found there, a decimal number or numeric string
+// with at most 9 fractional digits, exactly to Wei
+func gweiField(doc interface{}, path string) (*big.Int, error) {
+	v := doc
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("gas api field %q not found", path)
+		}
+		if v, ok = obj[key]; !ok {
+			return nil, fmt.Errorf("gas api field %q not found", path)
+		}
+	}
+
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		return nil, fmt.Errorf("gas api field %q is not a number", path)
+	}
+	// Parse the decimal exactly; a float would round 9-digit fractions
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return nil, fmt.Errorf("gas api field %q has invalid value %q", path, s)
+	}
+	if len(frac) > gweiDecimals {
+		return nil, fmt.Errorf("gas api field %q value %q has more than %d decimal places", path, s, gweiDecimals)
+	}
+	wei, _ := new(big.Int).SetString("0"+whole+frac+strings.Repeat("0", gweiDecimals-len(frac)), 10)
+	return wei, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGasAPIOracle(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		fields GasAPIFields
+	}{
+		{
+			name:   "default",
+			body:   `{"slow": 12, "standard": 15.5, "fast": 21}`,
+			fields: DefaultGasAPIFields,
+		},
+		{
+			name:   "etherscan",
+			body:   `{"status":"1","message":"OK","result":{"SafeGasPrice":"12","ProposeGasPrice":"15.5","FastGasPrice":"21"}}`,
+			fields: EtherscanGasAPIFields,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+				rw.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			fees, err := NewGasAPIOracle(srv.URL, tt.fields).Fees(context.Background())
+			if err != nil {
+				t.Fatalf("Fees: %v", err)
+			}
+			if fees.Slow.Cmp(gwei(12)) != 0 {
+				t.Errorf("slow = %v, want %v", fees.Slow, gwei(12))
+			}
+			if want := big.NewInt(15500000000); fees.Standard.Cmp(want) != 0 {
+				t.Errorf("standard = %v, want %v", fees.Standard, want)
+			}
+			if fees.Fast.Cmp(gwei(21)) != 0 {
+				t.Errorf("fast = %v, want %v", fees.Fast, gwei(21))
+			}
+		})
+	}
+}
+
+func TestGasAPIOracleMissingField(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`{"slow": 12, "standard": 15}`))
+	}))
+	defer srv.Close()
+
+	if _, err := NewGasAPIOracle(srv.URL, DefaultGasAPIFields).Fees(context.Background()); err == nil {
+		t.Fatal("expected error for missing fast field")
+	}
+}
+
+func TestGasAPIOracleExactGwei(t *testing.T) {
+	serve := func(body string) *GasAPIOracle {
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.Write([]byte(body))
+		}))
+		t.Cleanup(srv.Close)
+		return NewGasAPIOracle(srv.URL, DefaultGasAPIFields)
+	}
+
+	// 64-bit floats round this down by a Wei
+	fees, err := serve(`{"slow": "0.000000001", "standard": 33.000261327, "fast": "33.000261327"}`).Fees(context.Background())
+	if err != nil {
+		t.Fatalf("Fees: %v", err)
+	}
+	want := big.NewInt(33000261327)
+	if fees.Slow.Cmp(big.NewInt(1)) != 0 || fees.Standard.Cmp(want) != 0 || fees.Fast.Cmp(want) != 0 {
+		t.Fatalf("fees = %v %v %v, want 1 %v %v", fees.Slow, fees.Standard, fees.Fast, want, want)
+	}
+
+	for _, value := range []string{`"1.0000000001"`, `"-1"`, `"1e9"`, `""`} {
+		body := `{"slow": 1, "standard": 1, "fast": ` + value + `}`
+		if _, err := serve(body).Fees(context.Background()); err == nil {
+			t.Errorf("accepted fast = %s", value)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// DefaultDerivationPath is the BIP-44 path of the first Ethereum account, as
+// used by MetaMask, Ledger Live and most other wallets
+const DefaultDerivationPath = "m/44'/60'/0'/0/0"
+
+// DeriveFromMnemonic derives the key at a BIP-32 derivation path from a
+// BIP-39 mnemonic with an empty passphrase. The mnemonic's words and
+// checksum are validated. An empty path means DefaultDerivationPath.
+func DeriveFromMnemonic(mnemonic string, path string) (*ecdsa.PrivateKey, common.Address, error) {
+	if path == "" {
+		path = DefaultDerivationPath
+	}
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("invalid derivation path: %w", err)
+	}
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+
+	key, chainCode := hdMasterKey(seed)
+	for _, index := range derivationPath {
+		if key, chainCode, err = hdChildKey(key, chainCode, index); err != nil {
+			return nil, common.Address{}, fmt.Errorf("failed to derive %s: %w", path, err)
+		}
+	}
+	privateKey, err := crypto.ToECDSA(common.LeftPadBytes(key.Bytes(), 32))
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("failed to derive %s: %w", path, err)
+	}
+	return privateKey, PrivateKeyToAddress(privateKey), nil
+}
+
+// DeriveAccount derives the account at index under the standard Ethereum
+// path m/44'/60'/0'/0/index, matching the accounts MetaMask creates from the
+// same seed phrase
+func DeriveAccount(mnemonic string, index uint32) (*ecdsa.PrivateKey, common.Address, error) {
+	return DeriveFromMnemonic(mnemonic, fmt.Sprintf("m/44'/60'/0'/0/%d", index))
+}
+
+// hdMasterKey returns the BIP-32 master private key and chain code of seed
+func hdMasterKey(seed []byte) (*big.Int, []byte) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	return new(big.Int).SetBytes(sum[:32]), sum[32:]
+}
+
+// hdChildKey derives the BIP-32 child private key and chain code at index,
+// hardened when index has its top bit set
+func hdChildKey(key *big.Int, chainCode []byte, index uint32) (*big.Int, []byte, error) {
+	var data []byte
+	if index >= 0x80000000 {
+		data = append([]byte{0}, common.LeftPadBytes(key.Bytes(), 32)...)
+	} else {
+		parent, err := crypto.ToECDSA(common.LeftPadBytes(key.Bytes(), 32))
+		if err != nil {
+			return nil, nil, err
+		}
+		data = crypto.CompressPubkey(&parent.PublicKey)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	n := crypto.S256().Params().N
+	tweak := new(big.Int).SetBytes(sum[:32])
+	if tweak.Cmp(n) >= 0 {
+		return nil, nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+	child := tweak.Add(tweak, key)
+	child.Mod(child, n)
+	if child.Sign() == 0 {
+		return nil, nil, fmt.Errorf("invalid child key at index %d", index)
+	}
+	return child, sum[32:], nil
+}
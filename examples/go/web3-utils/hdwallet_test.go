@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// hardhatMnemonic is the default development mnemonic of Hardhat and Anvil
+const hardhatMnemonic = "test test test test test test test test test test test junk"
+
+func TestDeriveFromMnemonic(t *testing.T) {
+	key, addr, err := DeriveFromMnemonic(hardhatMnemonic, "")
+	if err != nil {
+		t.Fatalf("DeriveFromMnemonic: %v", err)
+	}
+	if want := common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"); addr != want {
+		t.Fatalf("address = %s, want %s", addr.Hex(), want.Hex())
+	}
+	if got := hexutil.Encode(crypto.FromECDSA(key)); got != "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80" {
+		t.Fatalf("private key = %s", got)
+	}
+
+	_, explicit, err := DeriveFromMnemonic(hardhatMnemonic, DefaultDerivationPath)
+	if err != nil || explicit != addr {
+		t.Fatalf("explicit default path = %s, %v", explicit.Hex(), err)
+	}
+
+	if _, _, err := DeriveFromMnemonic("test test test test test test test test test test test test", ""); err == nil {
+		t.Fatal("expected error for bad checksum")
+	}
+	if _, _, err := DeriveFromMnemonic(hardhatMnemonic, "m/44'/60'/x"); err == nil {
+		t.Fatal("expected error for invalid path")
+	}
+}
+
+func TestDeriveAccount(t *testing.T) {
+	for index, want := range []string{
+		"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
+		"0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+		"0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC",
+	} {
+		_, addr, err := DeriveAccount(hardhatMnemonic, uint32(index))
+		if err != nil {
+			t.Fatalf("DeriveAccount(%d): %v", index, err)
+		}
+		if addr != common.HexToAddress(want) {
+			t.Fatalf("account %d = %s, want %s", index, addr.Hex(), want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNoGasHistory is returned when an analysis needs samples but none are recorded
+var ErrNoGasHistory = errors.New("no gas price history recorded")
+
+// GasSample is a gas price observed at a point in time
+type GasSample struct {
+	Time  time.Time
+	Price *big.Int
+}
+
+// GasPriceHistory is a bounded, concurrency-safe ring buffer of gas price
+// samples. Once full, recording a new sample evicts the oldest one.
+type GasPriceHistory struct {
+	mu      sync.RWMutex
+	samples []GasSample
+	next    int
+	full    bool
+}
+
+// NewGasPriceHistory creates a history holding at most capacity samples
+func NewGasPriceHistory(capacity int) *GasPriceHistory {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &GasPriceHistory{samples: make([]GasSample, capacity)}
+}
+
+// Record appends a gas price sample taken at the given time
+func (h *GasPriceHistory) Record(price *big.Int, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.samples[h.next] = GasSample{Time: at, Price: new(big.Int).Set(price)}
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Len returns the number of recorded samples
+func (h *GasPriceHistory) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.full {
+		return len(h.samples)
+	}
+	return h.next
+}
+
+// Samples returns a copy of the recorded samples, oldest first
+func (h *GasPriceHistory) Samples() []GasSample {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if !h.full {
+		return append([]GasSample(nil), h.samples[:h.next]...)
+	}
+	out := make([]GasSample, 0, len(h.samples))
+	out = append(out, h.samples[h.next:]...)
+	return append(out, h.samples[:h.next]...)
+}
+
+// CheapestWindow suggests the cheapest time to transact based on the recorded
+// samples. With hourOfDay set it returns the UTC hour (0-23) with the lowest
+// average gas price, otherwise the weekday (0=Sunday..6=Saturday). The
+// average price for that window is returned alongside it.
+func (h *GasPriceHistory) CheapestWindow(hourOfDay bool) (int, *big.Int, error) {
+	samples := h.Samples()
+	if len(samples) == 0 {
+		return 0, nil, ErrNoGasHistory
+	}
+
+	buckets := 7
+	if hourOfDay {
+		buckets = 24
+	}
+	sums := make([]*big.Int, buckets)
+	counts := make([]int64, buckets)
+	for _, s := range samples {
+		t := s.Time.UTC()
+		idx := int(t.Weekday())
+		if hourOfDay {
+			idx = t.Hour()
+		}
+		if sums[idx] == nil {
+			sums[idx] = new(big.Int)
+		}
+		sums[idx].Add(sums[idx], s.Price)
+		counts[idx]++
+	}
+
+	best := -1
+	var bestAvg *big.Int
+	for i := range sums {
+		if counts[i] == 0 {
+			continue
+		}
+		avg := new(big.Int).Div(sums[i], big.NewInt(counts[i]))
+		if bestAvg == nil || avg.Cmp(bestAvg) < 0 {
+			best, bestAvg = i, avg
+		}
+	}
+	return best, bestAvg, nil
+}
+
+// Percentile returns the recorded price at the given percentile (0-100],
+// using the nearest-rank method
+func (h *GasPriceHistory) Percentile(percentile float64) (*big.Int, error) {
+	if percentile <= 0 || percentile > 100 {
+		return nil, fmt.Errorf("percentile must be in (0, 100], got %v", percentile)
+	}
+	samples := h.Samples()
+	if len(samples) == 0 {
+		return nil, ErrNoGasHistory
+	}
+
+	prices := make([]*big.Int, len(samples))
+	for i, s := range samples {
+		prices[i] = s.Price
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Cmp(prices[j]) < 0 })
+	rank := int(math.Ceil(percentile / 100 * float64(len(prices))))
+	return new(big.Int).Set(prices[rank-1]), nil
+}
+
+// IsHistoricallyCheap reports whether the current gas price is below the
+// given percentile of the prices recorded by WithGasPriceHistory, e.g. 20 to
+// only transact when gas is in the bottom 20%. The percentile price is
+// returned as the threshold. The current price is fetched after the
+// threshold is computed so it does not count towards it.
+func (w *Web3Utils) IsHistoricallyCheap(ctx context.Context, percentile float64) (bool, *big.Int, error) {
+	if w.gasHistory == nil {
+		return false, nil, ErrNoGasHistory
+	}
+	threshold, err := w.gasHistory.Percentile(percentile)
+	if err != nil {
+		return false, nil, err
+	}
+	price, err := w.GetGasPrice(ctx)
+	if err != nil {
+		return false, nil, err
+	}
+	return price.Cmp(threshold) < 0, threshold, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func gwei(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e9))
+}
+
+func TestGasPriceHistoryRingBuffer(t *testing.T) {
+	h := NewGasPriceHistory(3)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := int64(1); i <= 5; i++ {
+		h.Record(gwei(i), start.Add(time.Duration(i)*time.Minute))
+	}
+
+	samples := h.Samples()
+	if len(samples) != 3 || h.Len() != 3 {
+		t.Fatalf("got %d samples, want 3", len(samples))
+	}
+	for i, want := range []int64{3, 4, 5} {
+		if samples[i].Price.Cmp(gwei(want)) != 0 {
+			t.Fatalf("sample %d = %s, want %d gwei", i, samples[i].Price, want)
+		}
+	}
+}
+
+func TestCheapestWindowHourOfDay(t *testing.T) {
+	h := NewGasPriceHistory(200)
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	for d := 0; d < 5; d++ {
+		for hour := 0; hour < 24; hour++ {
+			price := gwei(40)
+			if hour == 3 {
+				price = gwei(8 + int64(d))
+			}
+			h.Record(price, day.AddDate(0, 0, d).Add(time.Duration(hour)*time.Hour))
+		}
+	}
+
+	hour, avg, err := h.CheapestWindow(true)
+	if err != nil {
+		t.Fatalf("CheapestWindow: %v", err)
+	}
+	if hour != 3 {
+		t.Fatalf("cheapest hour = %d, want 3", hour)
+	}
+	if avg.Cmp(gwei(10)) != 0 {
+		t.Fatalf("average = %s, want 10 gwei", avg)
+	}
+}
+
+func TestCheapestWindowDayOfWeek(t *testing.T) {
+	h := NewGasPriceHistory(50)
+	sunday := time.Date(2024, 3, 3, 12, 0, 0, 0, time.UTC)
+	for d := 0; d < 14; d++ {
+		price := gwei(30)
+		if sunday.AddDate(0, 0, d).Weekday() == time.Saturday {
+			price = gwei(12)
+		}
+		h.Record(price, sunday.AddDate(0, 0, d))
+	}
+
+	day, _, err := h.CheapestWindow(false)
+	if err != nil {
+		t.Fatalf("CheapestWindow: %v", err)
+	}
+	if time.Weekday(day) != time.Saturday {
+		t.Fatalf("cheapest day = %v, want Saturday", time.Weekday(day))
+	}
+}
+
+func TestCheapestWindowEmpty(t *testing.T) {
+	if _, _, err := NewGasPriceHistory(10).CheapestWindow(true); !errors.Is(err, ErrNoGasHistory) {
+		t.Fatalf("err = %v, want ErrNoGasHistory", err)
+	}
+}
+
+func TestGetGasPriceRecordsHistory(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_gasPrice", "0x3b9aca00")
+
+	h := NewGasPriceHistory(10)
+	w := m.dial(t, WithGasPriceHistory(h))
+	for i := 0; i < 2; i++ {
+		if _, err := w.GetGasPrice(context.Background()); err != nil {
+			t.Fatalf("GetGasPrice: %v", err)
+		}
+	}
+	if h.Len() != 2 {
+		t.Fatalf("history has %d samples, want 2", h.Len())
+	}
+}
+
+func TestIsHistoricallyCheap(t *testing.T) {
+	// 10 samples at 11..20 gwei: the 20th percentile is 12 gwei
+	h := NewGasPriceHistory(10)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := int64(0); i < 10; i++ {
+		h.Record(gwei(20-i), start.Add(time.Duration(i)*time.Hour))
+	}
+
+	m := newMockRPC(t)
+	m.result("eth_gasPrice", "0x2540be400") // 10 gwei
+	w := m.dial(t, WithGasPriceHistory(h))
+
+	cheap, threshold, err := w.IsHistoricallyCheap(context.Background(), 20)
+	if err != nil {
+		t.Fatalf("IsHistoricallyCheap: %v", err)
+	}
+	if threshold.Cmp(gwei(12)) != 0 {
+		t.Fatalf("threshold = %v, want %v", threshold, gwei(12))
+	}
+	if !cheap {
+		t.Fatal("10 gwei not reported cheap against a 12 gwei threshold")
+	}
+
+	m.result("eth_gasPrice", "0x37e11d600") // 15 gwei
+	if cheap, _, err := w.IsHistoricallyCheap(context.Background(), 20); err != nil || cheap {
+		t.Fatalf("IsHistoricallyCheap(15 gwei) = %v, %v; want false", cheap, err)
+	}
+
+	if _, _, err := m.dial(t).IsHistoricallyCheap(context.Background(), 20); !errors.Is(err, ErrNoGasHistory) {
+		t.Fatalf("err = %v, want ErrNoGasHistory", err)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+)
+
+// KeystoreScryptN and KeystoreScryptP are the scrypt parameters SaveKeystore
+// encrypts keys with. They default to go-ethereum's standard strength;
+// lowering them makes files faster to open and easier to brute-force.
+var (
+	KeystoreScryptN = keystore.StandardScryptN
+	KeystoreScryptP = keystore.StandardScryptP
+)
+
+// LoadKeystore decrypts a go-ethereum (Web3 Secret Storage V3) keystore file
+// and returns its private key and address
+func LoadKeystore(path string, passphrase string) (*ecdsa.PrivateKey, common.Address, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	key, err := keystore.DecryptKey(data, passphrase)
+	if err != nil {
+		return nil, common.Address{}, fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+	return key.PrivateKey, key.Address, nil
+}
+
+// SaveKeystore encrypts privateKey with passphrase and writes it to dir as a
+// V3 keystore file named like geth's, UTC--<timestamp>--<address>. The file
+// is readable only by its owner. It returns the file's path.
+func SaveKeystore(privateKey *ecdsa.PrivateKey, passphrase string, dir string) (string, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate key id: %w", err)
+	}
+	key := &keystore.Key{Id: id, Address: PrivateKeyToAddress(privateKey), PrivateKey: privateKey}
+	data, err := keystore.EncryptKey(key, passphrase, KeystoreScryptN, KeystoreScryptP)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt key: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create keystore directory: %w", err)
+	}
+	timestamp := time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z")
+	path := filepath.Join(dir, fmt.Sprintf("UTC--%s--%x", timestamp, key.Address.Bytes()))
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write keystore: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	n, p := KeystoreScryptN, KeystoreScryptP
+	KeystoreScryptN, KeystoreScryptP = keystore.LightScryptN, keystore.LightScryptP
+	t.Cleanup(func() { KeystoreScryptN, KeystoreScryptP = n, p })
+
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := PrivateKeyToAddress(key)
+	dir := filepath.Join(t.TempDir(), "keys")
+
+	path, err := SaveKeystore(key, "correct horse", dir)
+	if err != nil {
+		t.Fatalf("SaveKeystore: %v", err)
+	}
+	if filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), "UTC--") {
+		t.Fatalf("unexpected keystore path %s", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("keystore mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	loaded, loadedAddr, err := LoadKeystore(path, "correct horse")
+	if err != nil {
+		t.Fatalf("LoadKeystore: %v", err)
+	}
+	if loadedAddr != addr {
+		t.Fatalf("address = %s, want %s", loadedAddr.Hex(), addr.Hex())
+	}
+	if string(crypto.FromECDSA(loaded)) != string(crypto.FromECDSA(key)) {
+		t.Fatal("recovered private key differs")
+	}
+
+	if _, _, err := LoadKeystore(path, "wrong"); err == nil {
+		t.Fatal("expected error for wrong passphrase")
+	}
+}
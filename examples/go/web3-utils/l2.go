@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// L2FeeConfig holds the parameters a rollup uses to charge for posting a
+// transaction's data to L1, as in the OP Stack (pre-Ecotone) fee formula:
+//
+//	l1Fee = (calldataGas(tx) + Overhead) * l1BaseFee * Scalar / 10^ScalarDecimals
+type L2FeeConfig struct {
+	Overhead       uint64
+	Scalar         uint64
+	ScalarDecimals uint8
+}
+
+// OptimismL2FeeConfig holds the Bedrock fee parameters of OP Mainnet
+var OptimismL2FeeConfig = L2FeeConfig{Overhead: 188, Scalar: 684000, ScalarDecimals: 6}
+
+// L2DataCost approximates the L1 data availability part of an L2
+// transaction's fee: the calldata gas of the signed, encoded transaction
+// plus the rollup's fixed overhead, priced at l1BaseFee and scaled by the
+// configured scalar (see WithL2FeeConfig)
+func (w *Web3Utils) L2DataCost(tx *types.Transaction, l1BaseFee *big.Int) (*big.Int, error) {
+	if l1BaseFee == nil || l1BaseFee.Sign() < 0 {
+		return nil, fmt.Errorf("invalid l1 base fee %v", l1BaseFee)
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+
+	cfg := w.l2Fees
+	cost := new(big.Int).SetUint64(CalldataGasCost(raw) + cfg.Overhead)
+	cost.Mul(cost, l1BaseFee)
+	cost.Mul(cost, new(big.Int).SetUint64(cfg.Scalar))
+	return cost.Quo(cost, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(cfg.ScalarDecimals)), nil)), nil
+}
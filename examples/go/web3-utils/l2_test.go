@@ -0,0 +1,37 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestL2DataCost(t *testing.T) {
+	tx := legacyTx(t, 0, common.HexToAddress(testAddress), big.NewInt(1), gwei(1))
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gas := CalldataGasCost(raw)
+
+	m := newMockRPC(t)
+	cost, err := m.dial(t).L2DataCost(tx, gwei(10))
+	if err != nil {
+		t.Fatalf("L2DataCost: %v", err)
+	}
+	// (gas + 188) * 10 gwei * 0.684
+	want := new(big.Int).SetUint64((gas + 188) * 6840000000)
+	if cost.Cmp(want) != 0 {
+		t.Fatalf("cost = %v, want %v", cost, want)
+	}
+
+	custom := L2FeeConfig{Overhead: 0, Scalar: 2, ScalarDecimals: 0}
+	cost, err = m.dial(t, WithL2FeeConfig(custom)).L2DataCost(tx, gwei(10))
+	if err != nil {
+		t.Fatalf("L2DataCost with custom config: %v", err)
+	}
+	if want := new(big.Int).Mul(big.NewInt(int64(gas)*2), gwei(10)); cost.Cmp(want) != 0 {
+		t.Fatalf("custom cost = %v, want %v", cost, want)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// LatencyStats summarizes the round-trip latency of a series of RPC calls
+type LatencyStats struct {
+	Calls  int
+	Min    time.Duration
+	Median time.Duration
+	P95    time.Duration
+	Max    time.Duration
+}
+
+// newLatencyStats computes stats over samples using the nearest-rank
+// method for percentiles
+func newLatencyStats(samples []time.Duration) *LatencyStats {
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := func(p float64) time.Duration {
+		return sorted[int(math.Ceil(p/100*float64(len(sorted))))-1]
+	}
+	return &LatencyStats{
+		Calls:  len(sorted),
+		Min:    sorted[0],
+		Median: rank(50),
+		P95:    rank(95),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// BenchmarkRPC measures the endpoint's latency over the given number of
+// sequential eth_blockNumber requests, the cheapest call every node serves.
+// Any failed call aborts the benchmark.
+func (w *Web3Utils) BenchmarkRPC(ctx context.Context, calls int) (*LatencyStats, error) {
+	if calls < 1 {
+		return nil, fmt.Errorf("calls must be positive, got %d", calls)
+	}
+	samples := make([]time.Duration, calls)
+	for i := range samples {
+		start := time.Now()
+		if _, err := w.GetBlockNumber(ctx); err != nil {
+			return nil, fmt.Errorf("benchmark call %d: %w", i+1, err)
+		}
+		samples[i] = time.Since(start)
+	}
+	return newLatencyStats(samples), nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestLatencyStats(t *testing.T) {
+	samples := make([]time.Duration, 20)
+	for i := range samples {
+		// 20ms, 19ms, ..., 1ms
+		samples[i] = time.Duration(20-i) * time.Millisecond
+	}
+	got := *newLatencyStats(samples)
+	want := LatencyStats{Calls: 20, Min: time.Millisecond, Median: 10 * time.Millisecond, P95: 19 * time.Millisecond, Max: 20 * time.Millisecond}
+	if got != want {
+		t.Fatalf("stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestBenchmarkRPC(t *testing.T) {
+	delays := []time.Duration{2 * time.Millisecond, 10 * time.Millisecond, 4 * time.Millisecond, 6 * time.Millisecond}
+	m := newMockRPC(t)
+	m.handle("eth_blockNumber", func([]json.RawMessage) (interface{}, error) {
+		time.Sleep(delays[(m.callCount("eth_blockNumber")-1)%len(delays)])
+		return "0x64", nil
+	})
+
+	stats, err := m.dial(t).BenchmarkRPC(context.Background(), len(delays))
+	if err != nil {
+		t.Fatalf("BenchmarkRPC: %v", err)
+	}
+	if stats.Calls != 4 {
+		t.Fatalf("calls = %d, want 4", stats.Calls)
+	}
+	if stats.Min < 2*time.Millisecond || stats.Max < 10*time.Millisecond {
+		t.Fatalf("min %v max %v do not reflect the server delays", stats.Min, stats.Max)
+	}
+	if !(stats.Min <= stats.Median && stats.Median <= stats.P95 && stats.P95 <= stats.Max) {
+		t.Fatalf("stats out of order: %+v", stats)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ledgerHeader is the header row written by ExportLedger
+var ledgerHeader = []string{"hash", "block", "counterparty", "value", "direction"}
+
+// ExportLedger writes the native ETH transfers into and out of address over
+// blocks fromBlock to toBlock as CSV to out, one row per transfer in chain
+// order: transaction hash, block number, counterparty, value in wei, and
+// direction ("in", "out" or "self"). Transactions are found with
+// AccountTransactions; those moving no ETH, such as token transfers and
+// contract calls, and reverted ones are left out.
+func (w *Web3Utils) ExportLedger(ctx context.Context, address string, fromBlock, toBlock uint64, out io.Writer) error {
+	hashes, err := w.AccountTransactions(ctx, address, fromBlock, toBlock)
+	if err != nil {
+		return err
+	}
+	account := common.HexToAddress(address)
+
+	cw := csv.NewWriter(out)
+	if err := cw.Write(ledgerHeader); err != nil {
+		return fmt.Errorf("failed to write ledger: %w", err)
+	}
+	for _, hash := range hashes {
+		tx, _, err := w.GetTransactionByHash(ctx, hash.Hex())
+		if err != nil {
+			return err
+		}
+		if tx.Value().Sign() == 0 || tx.To() == nil {
+			continue
+		}
+		receipt, err := w.GetTransactionReceipt(ctx, hash.Hex())
+		if err != nil {
+			return err
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+		sender, err := txSender(tx)
+		if err != nil {
+			return err
+		}
+
+		var counterparty common.Address
+		var direction string
+		switch {
+		case sender == account && *tx.To() == account:
+			counterparty, direction = account, "self"
+		case sender == account:
+			counterparty, direction = *tx.To(), "out"
+		case *tx.To() == account:
+			counterparty, direction = sender, "in"
+		default:
+			continue
+		}
+		row := []string{hash.Hex(), receipt.BlockNumber.String(), counterparty.Hex(), tx.Value().String(), direction}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write ledger: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write ledger: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestExportLedger(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := PrivateKeyToAddress(key)
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	outgoing, err := types.SignNewTx(key, types.HomesteadSigner{}, &types.LegacyTx{
+		GasPrice: gwei(10), Gas: 21000, To: &other, Value: big.NewInt(3),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	incoming := legacyTx(t, 0, account, big.NewInt(2), gwei(10))
+	reverted := legacyTx(t, 1, account, big.NewInt(5), gwei(10))
+	sender, err := txSender(incoming)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blocks := map[uint64][]*types.Transaction{
+		100: {outgoing},
+		101: {incoming, reverted},
+	}
+	txs := map[common.Hash]*types.Transaction{}
+	receipts := map[common.Hash]*types.Receipt{}
+	for n, block := range blocks {
+		for _, tx := range block {
+			txs[tx.Hash()] = tx
+			receipts[tx.Hash()] = mockReceipt(tx.Hash().Hex(), int64(n), types.ReceiptStatusSuccessful)
+		}
+	}
+	receipts[reverted.Hash()].Status = types.ReceiptStatusFailed
+
+	m := newMockRPC(t)
+	m.handle("eth_getBlockByNumber", func(params []json.RawMessage) (interface{}, error) {
+		n, _ := blockTag(params[0])
+		return mockBlock(&types.Header{Number: new(big.Int).SetUint64(n)}, blocks[n]...), nil
+	})
+	m.result("eth_getLogs", []types.Log{})
+	m.handle("eth_getTransactionByHash", func(params []json.RawMessage) (interface{}, error) {
+		var hash common.Hash
+		if err := json.Unmarshal(params[0], &hash); err != nil {
+			return nil, err
+		}
+		return txs[hash], nil
+	})
+	m.handle("eth_getTransactionReceipt", func(params []json.RawMessage) (interface{}, error) {
+		var hash common.Hash
+		if err := json.Unmarshal(params[0], &hash); err != nil {
+			return nil, err
+		}
+		return receipts[hash], nil
+	})
+
+	var buf bytes.Buffer
+	if err := m.dial(t).ExportLedger(context.Background(), account.Hex(), 100, 101, &buf); err != nil {
+		t.Fatalf("ExportLedger: %v", err)
+	}
+	want := strings.Join([]string{
+		"hash,block,counterparty,value,direction",
+		outgoing.Hash().Hex() + ",100," + other.Hex() + ",3,out",
+		incoming.Hash().Hex() + ",101," + sender.Hex() + ",2,in",
+	}, "\n") + "\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("ledger =\n%s\nwant\n%s", got, want)
+	}
+}
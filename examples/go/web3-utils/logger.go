@@ -0,0 +1,30 @@
+package main
+
+import "time"
+
+// Logger receives a record of every RPC call made by a Web3Utils instance,
+// e.g. to emit structured logs or export metrics
+type Logger interface {
+	// LogCall is called once per call after any retries, with the name of
+	// the Web3Utils method that made it (as reported by Stats), how long it
+	// took and the error it returned, if any. It may be called concurrently.
+	LogCall(method string, duration time.Duration, err error)
+}
+
+// WarningLogger is an optional extension of Logger. A Logger that implements
+// it is also told when a method degrades instead of failing, such as
+// EstimateGas returning its fallback limit.
+type WarningLogger interface {
+	Logger
+	// LogWarning is called with the Web3Utils method that degraded, what it
+	// did instead and the error that caused it. It may be called
+	// concurrently.
+	LogWarning(method string, msg string, err error)
+}
+
+// warn reports a degraded result to the Logger if it is a WarningLogger
+func (w *Web3Utils) warn(method string, msg string, err error) {
+	if l, ok := w.logger.(WarningLogger); ok {
+		l.LogWarning(method, msg, err)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type loggedCall struct {
+	method   string
+	duration time.Duration
+	err      error
+}
+
+type loggedWarning struct {
+	method string
+	msg    string
+	err    error
+}
+
+// captureLogger records every logged call and warning
+type captureLogger struct {
+	mu       sync.Mutex
+	calls    []loggedCall
+	warnings []loggedWarning
+}
+
+func (l *captureLogger) LogCall(method string, duration time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, loggedCall{method, duration, err})
+}
+
+func (l *captureLogger) LogWarning(method string, msg string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warnings = append(l.warnings, loggedWarning{method, msg, err})
+}
+
+// callsTo returns the logged calls made by method
+func (l *captureLogger) callsTo(method string) []loggedCall {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var calls []loggedCall
+	for _, c := range l.calls {
+		if c.method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+func TestLoggerRecordsCalls(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_getBalance", "0x1")
+	logger := &captureLogger{}
+	w := m.dial(t, WithLogger(logger))
+
+	if _, err := w.GetBalance(context.Background(), testAddress); err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	calls := logger.callsTo("GetBalance")
+	if len(calls) != 1 {
+		t.Fatalf("logged %d GetBalance calls, want 1: %+v", len(calls), logger.calls)
+	}
+	if calls[0].duration <= 0 || calls[0].err != nil {
+		t.Fatalf("logged call = %+v, want a duration and no error", calls[0])
+	}
+
+	// Failures are logged with their error
+	if _, err := w.GetBlockNumber(context.Background()); err == nil {
+		t.Fatal("expected GetBlockNumber to fail against the mock")
+	}
+	if calls := logger.callsTo("GetBlockNumber"); len(calls) != 1 || calls[0].err == nil {
+		t.Fatalf("logged GetBlockNumber calls = %+v, want one with an error", calls)
+	}
+}
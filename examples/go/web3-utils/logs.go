@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// LogsByTx returns the event logs emitted by a transaction
+func (w *Web3Utils) LogsByTx(ctx context.Context, txHash string) ([]types.Log, error) {
+	receipt, err := w.GetTransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := make([]types.Log, len(receipt.Logs))
+	for i, l := range receipt.Logs {
+		logs[i] = *l
+	}
+	return logs, nil
+}
+
+// EventTopic returns the topic hash of an event signature such as
+// "Transfer(address,address,uint256)", for use as topic 0 in log filters.
+// Whitespace in the signature is ignored.
+func EventTopic(signature string) common.Hash {
+	return crypto.Keccak256Hash([]byte(strings.Join(strings.Fields(signature), "")))
+}
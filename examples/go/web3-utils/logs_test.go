@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const testTxHash = "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"
+
+func TestLogsByTx(t *testing.T) {
+	hash := common.HexToHash(testTxHash)
+	token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	receipt := &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      hash,
+		BlockNumber: big.NewInt(100),
+		GasUsed:     60000,
+	}
+	for i := 0; i < 3; i++ {
+		receipt.Logs = append(receipt.Logs, &types.Log{
+			Address: token,
+			Topics:  []common.Hash{common.BigToHash(big.NewInt(int64(i)))},
+			Data:    []byte{byte(i)},
+			TxHash:  hash,
+			Index:   uint(i),
+		})
+	}
+
+	m := newMockRPC(t)
+	m.result("eth_getTransactionReceipt", receipt)
+
+	logs, err := m.dial(t).LogsByTx(context.Background(), testTxHash)
+	if err != nil {
+		t.Fatalf("LogsByTx: %v", err)
+	}
+	if len(logs) != 3 {
+		t.Fatalf("got %d logs, want 3", len(logs))
+	}
+	for i, l := range logs {
+		if l.Index != uint(i) || l.Address != token || l.Topics[0] != common.BigToHash(big.NewInt(int64(i))) {
+			t.Fatalf("log %d = %+v, unexpected contents", i, l)
+		}
+	}
+}
+
+func TestEventTopic(t *testing.T) {
+	want := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	if got := EventTopic("Transfer(address,address,uint256)"); got != want {
+		t.Fatalf("EventTopic = %s, want %s", got.Hex(), want.Hex())
+	}
+	if got := EventTopic("Transfer(address, address, uint256)"); got != want {
+		t.Fatalf("EventTopic with spaces = %s, want %s", got.Hex(), want.Hex())
+	}
+}
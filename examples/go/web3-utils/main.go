@@ -3,15 +3,22 @@ package main
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 const (
@@ -19,45 +26,211 @@ const (
 	EthereumRPC = "https://eth.llamarpc.com"
 )
 
+// ErrZeroBalance is returned by GetBalance for empty accounts when
+// WithErrorOnZeroBalance is enabled
+var ErrZeroBalance = errors.New("address has zero balance")
+
 // Web3Utils provides utility functions for Ethereum interaction
 type Web3Utils struct {
-	client *ethclient.Client
+	endpoints *endpointSet
+
+	errorOnZeroBalance bool
+	gasHistory         *GasPriceHistory
+	retry              RetryConfig
+	gasFallback        uint64
+	batchWindow        time.Duration
+	ensCache           *ensCache
+	beacon             BeaconConfig
+	l2Fees             L2FeeConfig
+	metrics            *rpcMetrics
+	logger             Logger
+	logChunkSize       uint64
+	blockTime          BlockTimeEstimator
+	nonces             *NonceManager
+	dustMinSenders     int
+
+	life *lifecycle
+
+	// chainID caches the result of ChainID
+	chainIDMu sync.Mutex
+	chainID   *big.Int
+
+	// settings are the chain profile dependent settings, completed by the
+	// profile once the chain ID is known
+	profileMu sync.RWMutex
+	settings  chainSettings
+
+	// headsErr is the error that ended the latest SubscribeNewHeads
+	// subscription
+	headsMu  sync.Mutex
+	headsErr error
 }
 
 // NewWeb3Utils creates a new Web3Utils instance
-func NewWeb3Utils(rpcURL string) (*Web3Utils, error) {
-	client, err := ethclient.Dial(rpcURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Ethereum client: %v", err)
+func NewWeb3Utils(rpcURL string, opts ...Option) (*Web3Utils, error) {
+	w := newWeb3Utils([]string{rpcURL}, opts)
+	if _, err := w.endpoints.client(0); err != nil {
+		return nil, fmt.Errorf("failed to connect to Ethereum client: %w", err)
+	}
+	return w, nil
+}
+
+// newWeb3Utils applies opts over the defaults for an instance using urls
+func newWeb3Utils(urls []string, opts []Option) *Web3Utils {
+	w := &Web3Utils{
+		ensCache:       newENSCache(DefaultENSCacheSize, DefaultENSCacheTTL, DefaultENSNegativeTTL),
+		beacon:         MainnetBeaconConfig,
+		l2Fees:         OptimismL2FeeConfig,
+		metrics:        newRPCMetrics(),
+		logChunkSize:   DefaultLogChunkSize,
+		dustMinSenders: DefaultDustMinSenders,
+		life:           newLifecycle(),
+		settings: chainSettings{
+			pollInterval:       DefaultPollInterval,
+			confirmationTarget: DefaultConfirmationTarget,
+			baseFeeBuffer:      DefaultBaseFeeBufferPercent,
+		},
 	}
+	w.nonces = NewNonceManager(w)
+	for _, opt := range opts {
+		opt(w)
+	}
+	w.endpoints = newEndpointSet(urls, w.dial)
+	return w
+}
 
-	return &Web3Utils{client: client}, nil
+// dial connects to rpcURL, routing HTTP traffic through the batching
+// transport when auto-batching is enabled
+func (w *Web3Utils) dial(rpcURL string) (*ethclient.Client, error) {
+	if w.batchWindow <= 0 || !strings.HasPrefix(rpcURL, "http") {
+		return ethclient.Dial(rpcURL)
+	}
+	httpClient := &http.Client{Transport: newBatchTransport(w.batchWindow)}
+	rpcClient, err := rpc.DialOptions(context.Background(), rpcURL, rpc.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(rpcClient), nil
 }
 
 // GetBalance retrieves the balance of an address
-func (w *Web3Utils) GetBalance(address string) (*big.Int, error) {
+func (w *Web3Utils) GetBalance(ctx context.Context, address string) (*big.Int, error) {
 	account := common.HexToAddress(address)
-	balance, err := w.client.BalanceAt(context.Background(), account, nil)
+	var balance *big.Int
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		balance, err = c.BalanceAt(ctx, account, nil)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get balance: %v", err)
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+	if w.errorOnZeroBalance && balance.Sign() == 0 {
+		return nil, ErrZeroBalance
+	}
+	return balance, nil
+}
+
+// BalanceAt retrieves the balance of an address at the given block, or at
+// the latest block if blockNumber is nil
+func (w *Web3Utils) BalanceAt(ctx context.Context, address string, blockNumber *big.Int) (*big.Int, error) {
+	account := common.HexToAddress(address)
+	var balance *big.Int
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		balance, err = c.BalanceAt(ctx, account, blockNumber)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance at block %s: %w", blockArg(blockNumber), err)
 	}
 	return balance, nil
 }
 
+// BalanceDelta returns the signed change in an address's balance from
+// fromBlock to toBlock. If fromBlock is after toBlock the blocks are
+// swapped, so the result always reads forward in time.
+func (w *Web3Utils) BalanceDelta(ctx context.Context, address string, fromBlock, toBlock *big.Int) (*big.Int, error) {
+	if fromBlock == nil || toBlock == nil {
+		return nil, fmt.Errorf("both block numbers are required")
+	}
+	if fromBlock.Cmp(toBlock) > 0 {
+		fromBlock, toBlock = toBlock, fromBlock
+	}
+
+	before, err := w.BalanceAt(ctx, address, fromBlock)
+	if err != nil {
+		return nil, err
+	}
+	after, err := w.BalanceAt(ctx, address, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Sub(after, before), nil
+}
+
+// BalanceHistory fetches the balance of an address at each of the given
+// blocks concurrently. Results are returned in the same order as blocks.
+func (w *Web3Utils) BalanceHistory(ctx context.Context, address string, blocks []uint64) ([]*big.Int, error) {
+	balances := make([]*big.Int, len(blocks))
+	errs := make([]error, len(blocks))
+
+	var wg sync.WaitGroup
+	for i, block := range blocks {
+		wg.Add(1)
+		go func(i int, block uint64) {
+			defer wg.Done()
+			balances[i], errs[i] = w.BalanceAt(ctx, address, new(big.Int).SetUint64(block))
+		}(i, block)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return balances, nil
+}
+
+// NonceAt retrieves the nonce of an address at the given block, or at the
+// latest block if blockNumber is nil
+func (w *Web3Utils) NonceAt(ctx context.Context, address string, blockNumber *big.Int) (uint64, error) {
+	account := common.HexToAddress(address)
+	var nonce uint64
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		nonce, err = c.NonceAt(ctx, account, blockNumber)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	return nonce, nil
+}
+
 // GetBlockNumber gets the latest block number
-func (w *Web3Utils) GetBlockNumber() (uint64, error) {
-	blockNumber, err := w.client.BlockNumber(context.Background())
+func (w *Web3Utils) GetBlockNumber(ctx context.Context) (uint64, error) {
+	var blockNumber uint64
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		blockNumber, err = c.BlockNumber(ctx)
+		return err
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to get block number: %v", err)
+		return 0, fmt.Errorf("failed to get block number: %w", err)
 	}
 	return blockNumber, nil
 }
 
 // GetGasPrice retrieves the current gas price
-func (w *Web3Utils) GetGasPrice() (*big.Int, error) {
-	gasPrice, err := w.client.SuggestGasPrice(context.Background())
+func (w *Web3Utils) GetGasPrice(ctx context.Context) (*big.Int, error) {
+	var gasPrice *big.Int
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		gasPrice, err = c.SuggestGasPrice(ctx)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get gas price: %v", err)
+		return nil, fmt.Errorf("failed to get gas price: %w", err)
+	}
+	if w.gasHistory != nil {
+		w.gasHistory.Record(gasPrice, time.Now())
 	}
 	return gasPrice, nil
 }
@@ -66,7 +239,7 @@ func (w *Web3Utils) GetGasPrice() (*big.Int, error) {
 func GeneratePrivateKey() (*ecdsa.PrivateKey, error) {
 	privateKey, err := crypto.GenerateKey()
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate private key: %v", err)
+		return nil, fmt.Errorf("failed to generate private key: %w", err)
 	}
 	return privateKey, nil
 }
@@ -81,23 +254,45 @@ func PrivateKeyToAddress(privateKey *ecdsa.PrivateKey) common.Address {
 	return crypto.PubkeyToAddress(*publicKeyECDSA)
 }
 
-// SignMessage signs a message with a private key
-func SignMessage(message []byte, privateKey *ecdsa.PrivateKey) ([]byte, error) {
-	hash := crypto.Keccak256Hash(message)
+// HashMessage returns the digest that is signed for a message. Unprefixed
+// messages are hashed as-is, as SignMessage does; prefixed messages get the
+// EIP-191 "\x19Ethereum Signed Message:\n" + length prefix used by
+// personal_sign.
+func HashMessage(message []byte, prefixed bool) common.Hash {
+	if prefixed {
+		prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))
+		return crypto.Keccak256Hash([]byte(prefix), message)
+	}
+	return crypto.Keccak256Hash(message)
+}
+
+// DeterministicSign signs a 32-byte digest with a deterministic nonce
+// (RFC 6979), so signing the same digest with the same key always yields
+// byte-identical signatures. Callers may rely on this, e.g. to use
+// signatures as deduplication keys.
+func DeterministicSign(hash common.Hash, privateKey *ecdsa.PrivateKey) ([]byte, error) {
+	// crypto.Sign is backed by libsecp256k1, which derives nonces per RFC 6979.
 	signature, err := crypto.Sign(hash.Bytes(), privateKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign message: %v", err)
+		return nil, fmt.Errorf("failed to sign message: %w", err)
 	}
 	return signature, nil
 }
 
-// VerifySignature verifies a signature against a message and address
+// SignMessage signs a message with a private key
+func SignMessage(message []byte, privateKey *ecdsa.PrivateKey) ([]byte, error) {
+	return DeterministicSign(HashMessage(message, false), privateKey)
+}
+
+// VerifySignature verifies a 65-byte [R || S || V] signature against a
+// message and address. V may be either 0/1, as produced by SignMessage, or
+// 27/28, as produced by most wallets.
 func VerifySignature(message []byte, signature []byte, address common.Address) bool {
-	hash := crypto.Keccak256Hash(message)
+	hash := HashMessage(message, false)
 
-	// Remove the recovery ID from signature
-	if len(signature) == 65 {
-		signature = signature[:64]
+	signature, err := normalizeSignature(signature)
+	if err != nil {
+		return false
 	}
 
 	pubKey, err := crypto.SigToPub(hash.Bytes(), signature)
@@ -109,42 +304,84 @@ func VerifySignature(message []byte, signature []byte, address common.Address) b
 	return recoveredAddr == address
 }
 
+// floatPrecision is the mantissa precision, in bits, used by the unit
+// conversions. Zero lets big.Float pick the precision of the operands.
+var floatPrecision atomic.Uint32
+
+// SetFloatPrecision sets the mantissa precision in bits used by WeiToEth,
+// EthToWei, WeiToGwei and GweiToWei. The default, 0, keeps big.Float's
+// automatic choice: the larger of the operands' precisions, which is at least
+// 64 bits and can round values with many significant digits. Financial code
+// that needs every Wei to survive a conversion should set 256 or more.
+func SetFloatPrecision(bits uint) {
+	floatPrecision.Store(uint32(bits))
+}
+
+// newFloat returns a big.Float using the configured precision
+func newFloat() *big.Float {
+	return new(big.Float).SetPrec(uint(floatPrecision.Load()))
+}
+
 // Wei converts ETH to Wei
 func WeiToEth(wei *big.Int) *big.Float {
-	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18))
+	return newFloat().Quo(newFloat().SetInt(wei), big.NewFloat(1e18))
 }
 
 // EthToWei converts Wei to ETH
 func EthToWei(eth *big.Float) *big.Int {
-	wei := new(big.Float).Mul(eth, big.NewFloat(1e18))
+	wei := newFloat().Mul(eth, big.NewFloat(1e18))
+	result := new(big.Int)
+	wei.Int(result)
+	return result
+}
+
+// WeiToGwei converts Wei to Gwei
+func WeiToGwei(wei *big.Int) *big.Float {
+	return newFloat().Quo(newFloat().SetInt(wei), big.NewFloat(1e9))
+}
+
+// GweiToWei converts Gwei to Wei, truncating fractions of a Wei
+func GweiToWei(gwei *big.Float) *big.Int {
+	wei := newFloat().Mul(gwei, big.NewFloat(1e9))
 	result := new(big.Int)
 	wei.Int(result)
 	return result
 }
 
 // GetTransactionByHash retrieves transaction details
-func (w *Web3Utils) GetTransactionByHash(txHash string) (*types.Transaction, bool, error) {
+func (w *Web3Utils) GetTransactionByHash(ctx context.Context, txHash string) (*types.Transaction, bool, error) {
 	hash := common.HexToHash(txHash)
-	tx, isPending, err := w.client.TransactionByHash(context.Background(), hash)
+	var (
+		tx        *types.Transaction
+		isPending bool
+	)
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		tx, isPending, err = c.TransactionByHash(ctx, hash)
+		return err
+	})
 	if err != nil {
-		return nil, false, fmt.Errorf("failed to get transaction: %v", err)
+		return nil, false, fmt.Errorf("failed to get transaction: %w", err)
 	}
 	return tx, isPending, nil
 }
 
 // GetTransactionReceipt retrieves the receipt of a transaction
-func (w *Web3Utils) GetTransactionReceipt(txHash string) (*types.Receipt, error) {
+func (w *Web3Utils) GetTransactionReceipt(ctx context.Context, txHash string) (*types.Receipt, error) {
 	hash := common.HexToHash(txHash)
-	receipt, err := w.client.TransactionReceipt(context.Background(), hash)
+	var receipt *types.Receipt
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		receipt, err = c.TransactionReceipt(ctx, hash)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get receipt: %v", err)
+		return nil, fmt.Errorf("failed to get receipt: %w", err)
 	}
 	return receipt, nil
 }
 
 // Close closes the Ethereum client connection
 func (w *Web3Utils) Close() {
-	w.client.Close()
+	w.endpoints.close()
 }
 
 func main() {
@@ -155,67 +392,7 @@ func main() {
 	}
 	defer utils.Close()
 
-	fmt.Println("🔗 Web3 Go Utilities Demo")
-	fmt.Println("=" + string(make([]byte, 50)))
-
-	// Get latest block number
-	blockNum, err := utils.GetBlockNumber()
-	if err != nil {
-		log.Printf("Error getting block number: %v", err)
-	} else {
-		fmt.Printf("\n📦 Latest Block: %d\n", blockNum)
-	}
-
-	// Get gas price
-	gasPrice, err := utils.GetGasPrice()
-	if err != nil {
-		log.Printf("Error getting gas price: %v", err)
-	} else {
-		gasPriceGwei := new(big.Float).Quo(
-			new(big.Float).SetInt(gasPrice),
-			big.NewFloat(1e9),
-		)
-		fmt.Printf("⛽ Gas Price: %.2f Gwei\n", gasPriceGwei)
-	}
-
-	// Generate new key pair
-	privateKey, err := GeneratePrivateKey()
-	if err != nil {
-		log.Fatalf("Error generating private key: %v", err)
-	}
-
-	address := PrivateKeyToAddress(privateKey)
-	privateKeyBytes := crypto.FromECDSA(privateKey)
-	privateKeyHex := hexutil.Encode(privateKeyBytes)
-
-	fmt.Printf("\n🔑 Generated New Key Pair:\n")
-	fmt.Printf("   Address: %s\n", address.Hex())
-	fmt.Printf("   Private Key: %s\n", privateKeyHex)
-
-	// Sign and verify message
-	message := []byte("Hello, Web3!")
-	signature, err := SignMessage(message, privateKey)
-	if err != nil {
-		log.Fatalf("Error signing message: %v", err)
-	}
-
-	fmt.Printf("\n✍️  Message Signature:\n")
-	fmt.Printf("   Message: %s\n", string(message))
-	fmt.Printf("   Signature: %s\n", hexutil.Encode(signature))
-
-	// Verify signature
-	isValid := VerifySignature(message, signature, address)
-	fmt.Printf("   Valid: %v\n", isValid)
-
-	// Example: Check Vitalik's balance
-	vitalikAddress := "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"
-	balance, err := utils.GetBalance(vitalikAddress)
-	if err != nil {
-		log.Printf("Error getting balance: %v", err)
-	} else {
-		ethBalance := WeiToEth(balance)
-		fmt.Printf("\n💰 Vitalik's Balance:\n")
-		fmt.Printf("   Address: %s\n", vitalikAddress)
-		fmt.Printf("   Balance: %.4f ETH\n", ethBalance)
+	if err := RunDemo(os.Stdout, utils); err != nil {
+		log.Fatalf("Error running demo: %v", err)
 	}
 }
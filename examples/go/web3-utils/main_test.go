@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testAddress = "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"
+
+func TestGetBalanceZeroBalanceOption(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_getBalance", "0x0")
+
+	balance, err := m.dial(t).GetBalance(context.Background(), testAddress)
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	if balance.Sign() != 0 {
+		t.Fatalf("balance = %s, want 0", balance)
+	}
+
+	_, err = m.dial(t, WithErrorOnZeroBalance(true)).GetBalance(context.Background(), testAddress)
+	if !errors.Is(err, ErrZeroBalance) {
+		t.Fatalf("err = %v, want ErrZeroBalance", err)
+	}
+}
+
+func TestGetBalanceNonZeroWithOption(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_getBalance", "0xde0b6b3a7640000")
+
+	balance, err := m.dial(t, WithErrorOnZeroBalance(true)).GetBalance(context.Background(), testAddress)
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	if balance.String() != "1000000000000000000" {
+		t.Fatalf("balance = %s, want 1 ETH", balance)
+	}
+}
+
+func TestNonceAt(t *testing.T) {
+	m := newMockRPC(t)
+	m.handle("eth_getTransactionCount", func(params []json.RawMessage) (interface{}, error) {
+		var block string
+		if err := json.Unmarshal(params[1], &block); err != nil {
+			return nil, err
+		}
+		if block == "0x3e8" {
+			return "0x7", nil
+		}
+		return "0x2a", nil
+	})
+	w := m.dial(t)
+
+	nonce, err := w.NonceAt(context.Background(), testAddress, big.NewInt(1000))
+	if err != nil {
+		t.Fatalf("NonceAt: %v", err)
+	}
+	if nonce != 7 {
+		t.Fatalf("nonce at block 1000 = %d, want 7", nonce)
+	}
+
+	nonce, err = w.NonceAt(context.Background(), testAddress, nil)
+	if err != nil {
+		t.Fatalf("NonceAt latest: %v", err)
+	}
+	if nonce != 42 {
+		t.Fatalf("latest nonce = %d, want 42", nonce)
+	}
+}
+
+func TestHashMessage(t *testing.T) {
+	message := []byte("Hello Joe")
+
+	if got, want := HashMessage(message, false), crypto.Keccak256Hash(message); got != want {
+		t.Fatalf("unprefixed hash = %s, want %s", got.Hex(), want.Hex())
+	}
+
+	prefixed := append([]byte("\x19Ethereum Signed Message:\n9"), message...)
+	if got, want := HashMessage(message, true), crypto.Keccak256Hash(prefixed); got != want {
+		t.Fatalf("prefixed hash = %s, want %s", got.Hex(), want.Hex())
+	}
+	// Matches the EIP-191 digest go-ethereum's accounts.TextHash produces.
+	want := common.HexToHash("0xa080337ae51c4e064c189e113edd0ba391df9206e2f49db658bb32cf2911730b")
+	if got := HashMessage(message, true); got != want {
+		t.Fatalf("prefixed hash = %s, want %s", got.Hex(), want.Hex())
+	}
+}
+
+func TestSignMessageSignsHashMessage(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("audit me")
+	sig, err := SignMessage(message, key)
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	pub, err := crypto.SigToPub(HashMessage(message, false).Bytes(), sig)
+	if err != nil {
+		t.Fatalf("SigToPub: %v", err)
+	}
+	if crypto.PubkeyToAddress(*pub) != PrivateKeyToAddress(key) {
+		t.Fatal("signature does not recover over HashMessage digest")
+	}
+}
+
+func TestVerifySignatureRecoveryID(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := PrivateKeyToAddress(key)
+	message := []byte("Hello, Web3!")
+	sig, err := SignMessage(message, key)
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+
+	wallet := append([]byte{}, sig...)
+	wallet[64] += 27
+	tests := []struct {
+		name string
+		sig  []byte
+	}{
+		{"v=0/1", sig},
+		{"v=27/28", wallet},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !VerifySignature(message, tt.sig, address) {
+				t.Fatal("valid signature rejected")
+			}
+			if VerifySignature([]byte("tampered"), tt.sig, address) {
+				t.Fatal("signature accepted for a different message")
+			}
+		})
+	}
+	if VerifySignature(message, sig[:64], address) {
+		t.Fatal("signature without a recovery id accepted")
+	}
+}
+
+func TestBalanceDelta(t *testing.T) {
+	balances := map[uint64]string{
+		100: "0xde0b6b3a7640000",  // 1 ETH
+		200: "0x29a2241af62c0000", // 3 ETH
+		300: "0x6f05b59d3b20000",  // 0.5 ETH
+	}
+	m := newMockRPC(t)
+	m.handle("eth_getBalance", func(params []json.RawMessage) (interface{}, error) {
+		block, _ := blockTag(params[1])
+		return balances[block], nil
+	})
+	w := m.dial(t)
+
+	oneEth, _ := new(big.Int).SetString("1000000000000000000", 10)
+	tests := []struct {
+		from, to int64
+		want     *big.Int
+	}{
+		{100, 200, new(big.Int).Mul(oneEth, big.NewInt(2))},
+		{200, 100, new(big.Int).Mul(oneEth, big.NewInt(2))},
+		{200, 300, new(big.Int).Neg(new(big.Int).Mul(big.NewInt(25e8), big.NewInt(1e9)))},
+	}
+	for _, tt := range tests {
+		delta, err := w.BalanceDelta(context.Background(), testAddress, big.NewInt(tt.from), big.NewInt(tt.to))
+		if err != nil {
+			t.Fatalf("BalanceDelta(%d, %d): %v", tt.from, tt.to, err)
+		}
+		if delta.Cmp(tt.want) != 0 {
+			t.Fatalf("BalanceDelta(%d, %d) = %s, want %s", tt.from, tt.to, delta, tt.want)
+		}
+	}
+}
+
+func TestFloatPrecision(t *testing.T) {
+	t.Cleanup(func() { SetFloatPrecision(0) })
+
+	wei, _ := new(big.Int).SetString("123456789123456789123456789", 10)
+	const want = "123456789.123456789123456789"
+
+	SetFloatPrecision(256)
+	if got := WeiToEth(wei).Text('f', 18); got != want {
+		t.Fatalf("WeiToEth = %s, want %s", got, want)
+	}
+	eth, _, err := big.ParseFloat(want, 10, 256, big.ToNearestEven)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := EthToWei(eth); got.Cmp(wei) != 0 {
+		t.Fatalf("EthToWei = %s, want %s", got, wei)
+	}
+}
+
+func TestBalanceHistory(t *testing.T) {
+	m := newMockRPC(t)
+	m.handle("eth_getBalance", func(params []json.RawMessage) (interface{}, error) {
+		block, _ := blockTag(params[1])
+		// answer later blocks first so completion order differs from input order
+		time.Sleep(time.Duration(300-block) * time.Millisecond / 10)
+		return hexutil.EncodeUint64(block * 10), nil
+	})
+
+	blocks := []uint64{100, 200, 300}
+	balances, err := m.dial(t).BalanceHistory(context.Background(), testAddress, blocks)
+	if err != nil {
+		t.Fatalf("BalanceHistory: %v", err)
+	}
+	if len(balances) != len(blocks) {
+		t.Fatalf("got %d balances, want %d", len(balances), len(blocks))
+	}
+	for i, block := range blocks {
+		if balances[i].Uint64() != block*10 {
+			t.Fatalf("balance[%d] = %s, want %d", i, balances[i], block*10)
+		}
+	}
+}
+
+func TestDeterministicSign(t *testing.T) {
+	key, err := crypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := HashMessage([]byte("dedup key"), false)
+
+	first, err := DeterministicSign(hash, key)
+	if err != nil {
+		t.Fatalf("DeterministicSign: %v", err)
+	}
+	second, err := DeterministicSign(hash, key)
+	if err != nil {
+		t.Fatalf("DeterministicSign: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Fatalf("signatures differ:\n%x\n%x", first, second)
+	}
+
+	viaSignMessage, err := SignMessage([]byte("dedup key"), key)
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	if !bytes.Equal(first, viaSignMessage) {
+		t.Fatal("SignMessage is not deterministic over the same digest")
+	}
+}
+
+func TestNetworkMethodsHonorContext(t *testing.T) {
+	// Every call hangs until the test ends, like an unresponsive endpoint
+	release := make(chan struct{})
+	m := newMockRPC(t)
+	t.Cleanup(func() { close(release) })
+	hang := func([]json.RawMessage) (interface{}, error) {
+		<-release
+		return "0x1", nil
+	}
+	for _, method := range []string{"eth_getBalance", "eth_blockNumber", "eth_gasPrice", "eth_getTransactionByHash", "eth_getTransactionReceipt"} {
+		m.handle(method, hang)
+	}
+	w := m.dial(t)
+
+	calls := map[string]func(ctx context.Context) error{
+		"GetBalance": func(ctx context.Context) error {
+			_, err := w.GetBalance(ctx, testAddress)
+			return err
+		},
+		"GetBlockNumber": func(ctx context.Context) error {
+			_, err := w.GetBlockNumber(ctx)
+			return err
+		},
+		"GetGasPrice": func(ctx context.Context) error {
+			_, err := w.GetGasPrice(ctx)
+			return err
+		},
+		"GetTransactionByHash": func(ctx context.Context) error {
+			_, _, err := w.GetTransactionByHash(ctx, testTxHash)
+			return err
+		},
+		"GetTransactionReceipt": func(ctx context.Context) error {
+			_, err := w.GetTransactionReceipt(ctx, testTxHash)
+			return err
+		},
+	}
+	for name, call := range calls {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		start := time.Now()
+		err := call(ctx)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: err = %v, want context.Canceled", name, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: returned after %v, want prompt return on cancel", name, elapsed)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ErrNoMetadata is returned when bytecode does not end with Solidity's
+// CBOR-encoded metadata or the metadata carries no IPFS hash
+var ErrNoMetadata = errors.New("bytecode carries no ipfs metadata hash")
+
+// base58Alphabet is the Bitcoin base58 alphabet used by IPFS CIDv0
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// VerifyMetadataHash reports whether the contract deployed at address was
+// compiled from the metadata whose IPFS hash is expectedIPFS. The Solidity
+// compiler appends a CBOR map to the runtime bytecode whose "ipfs" entry is
+// the multihash of the contract's metadata JSON, which pins the exact source
+// files and compiler settings. expectedIPFS may be a CIDv0 ("Qm...") or the
+// hex multihash. It returns ErrNoMetadata for code without such an entry,
+// including addresses without code.
+func (w *Web3Utils) VerifyMetadataHash(ctx context.Context, address string, expectedIPFS string) (bool, error) {
+	expected := strings.TrimSpace(expectedIPFS)
+	if strings.HasPrefix(expected, "0x") {
+		raw, err := hexutil.Decode(expected)
+		if err != nil {
+			return false, fmt.Errorf("invalid ipfs hash %q: %w", expectedIPFS, err)
+		}
+		expected = base58Encode(raw)
+	}
+
+	contract := common.HexToAddress(address)
+	var code []byte
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		code, err = c.CodeAt(ctx, contract, nil)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get code: %w", err)
+	}
+	actual, err := MetadataIPFSHash(code)
+	if err != nil {
+		return false, err
+	}
+	return actual == expected, nil
+}
+
+// MetadataIPFSHash extracts the IPFS hash of the metadata appended to
+// Solidity runtime bytecode and returns it as a CIDv0 string. The last two
+// bytes of the code give the length of the CBOR map that precedes them.
+func MetadataIPFSHash(code []byte) (string, error) {
+	if len(code) < 2 {
+		return "", ErrNoMetadata
+	}
+	size := int(binary.BigEndian.Uint16(code[len(code)-2:]))
+	if size == 0 || size > len(code)-2 {
+		return "", ErrNoMetadata
+	}
+	entries, err := decodeCBORMap(code[len(code)-2-size : len(code)-2])
+	if err != nil {
+		return "", ErrNoMetadata
+	}
+	hash, ok := entries["ipfs"].([]byte)
+	if !ok || len(hash) == 0 {
+		return "", ErrNoMetadata
+	}
+	return base58Encode(hash), nil
+}
+
+// decodeCBORMap decodes a CBOR map with text keys whose values are byte
+// strings, text strings, unsigned integers or booleans, which covers what
+// solc emits. The map must span all of data.
+func decodeCBORMap(data []byte) (map[string]interface{}, error) {
+	major, count, pos, err := cborHead(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	if major != 5 {
+		return nil, fmt.Errorf("cbor: expected a map, got major type %d", major)
+	}
+
+	entries := make(map[string]interface{}, count)
+	for i := uint64(0); i < count; i++ {
+		var key, value interface{}
+		if key, pos, err = cborItem(data, pos); err != nil {
+			return nil, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("cbor: map key is not a string")
+		}
+		if value, pos, err = cborItem(data, pos); err != nil {
+			return nil, err
+		}
+		entries[name] = value
+	}
+	if pos != len(data) {
+		return nil, fmt.Errorf("cbor: %d trailing bytes", len(data)-pos)
+	}
+	return entries, nil
+}
+
+// cborItem decodes the scalar item starting at pos and returns it with the
+// position after it
+func cborItem(data []byte, pos int) (interface{}, int, error) {
+	major, arg, pos, err := cborHead(data, pos)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch major {
+	case 0:
+		return arg, pos, nil
+	case 2, 3:
+		if arg > uint64(len(data)-pos) {
+			return nil, 0, fmt.Errorf("cbor: string overruns data")
+		}
+		end := pos + int(arg)
+		if major == 3 {
+			return string(data[pos:end]), end, nil
+		}
+		return data[pos:end], end, nil
+	case 7:
+		switch arg {
+		case 20:
+			return false, pos, nil
+		case 21:
+			return true, pos, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("cbor: unsupported item of major type %d", major)
+}
+
+// cborHead decodes the initial byte and argument of the item at pos
+func cborHead(data []byte, pos int) (major byte, arg uint64, next int, err error) {
+	if pos >= len(data) {
+		return 0, 0, 0, fmt.Errorf("cbor: unexpected end of data")
+	}
+	major, info := data[pos]>>5, data[pos]&0x1f
+	pos++
+	if info < 24 {
+		return major, uint64(info), pos, nil
+	}
+	if info > 27 {
+		return 0, 0, 0, fmt.Errorf("cbor: unsupported additional info %d", info)
+	}
+	n := 1 << (info - 24)
+	if n > len(data)-pos {
+		return 0, 0, 0, fmt.Errorf("cbor: unexpected end of data")
+	}
+	for _, b := range data[pos : pos+n] {
+		arg = arg<<8 | uint64(b)
+	}
+	return major, arg, pos + n, nil
+}
+
+// base58Encode encodes data in Bitcoin base58, preserving leading zero
+// bytes as '1's
+func base58Encode(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	n := new(big.Int).SetBytes(data)
+	base, mod := big.NewInt(58), new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, base, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	out = append(out, strings.Repeat("1", zeros)...)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
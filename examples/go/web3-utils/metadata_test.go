@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// metadataCID is the CIDv0 of the multihash 0x1220 followed by bytes 0..31
+const metadataCID = "QmNLfbof5rLekrACjeuLk9JmGZD2HDBHCU4z16iYKmx5SE"
+
+// metadataCode is runtime bytecode ending in solc 0.8.19 metadata:
+// {"ipfs": <multihash>, "solc": 0x000813} followed by its 0x0033 length
+const metadataCode = "0x6080604052" +
+	"a2" + "6469706673" + "5822" + "1220000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f" +
+	"64736f6c63" + "43000813" + "0033"
+
+func TestVerifyMetadataHash(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_getCode", metadataCode)
+	w := m.dial(t)
+	ctx := context.Background()
+
+	for _, expected := range []string{metadataCID, "0x1220000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"} {
+		ok, err := w.VerifyMetadataHash(ctx, testAddress, expected)
+		if err != nil {
+			t.Fatalf("VerifyMetadataHash(%s): %v", expected, err)
+		}
+		if !ok {
+			t.Fatalf("VerifyMetadataHash(%s) = false, want true", expected)
+		}
+	}
+	ok, err := w.VerifyMetadataHash(ctx, testAddress, "QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG")
+	if err != nil || ok {
+		t.Fatalf("VerifyMetadataHash(other) = %v, %v; want false", ok, err)
+	}
+
+	m = newMockRPC(t)
+	m.result("eth_getCode", "0x6080604052")
+	if _, err := m.dial(t).VerifyMetadataHash(ctx, testAddress, metadataCID); !errors.Is(err, ErrNoMetadata) {
+		t.Fatalf("err = %v, want ErrNoMetadata", err)
+	}
+}
+
+func TestBase58Encode(t *testing.T) {
+	tests := map[string]string{
+		"Hello World!":             "2NEpo7TZRRrLZSi2U",
+		"\x00\x00\x28\x7f\xb4\xcd": "11233QC4",
+		"":                         "",
+	}
+	for in, want := range tests {
+		if got := base58Encode([]byte(in)); got != want {
+			t.Errorf("base58Encode(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
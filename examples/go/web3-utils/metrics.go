@@ -0,0 +1,111 @@
+package main
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MethodStats counts the RPC activity of one Web3Utils method
+type MethodStats struct {
+	// Calls is the number of calls, each counted once however often it was
+	// retried
+	Calls uint64
+	// Failures is the number of calls that still failed after all retries
+	Failures uint64
+	// Retries is the number of extra attempts, including failovers to
+	// another endpoint
+	Retries uint64
+	// AverageLatency is the mean duration of a call, retries included
+	AverageLatency time.Duration
+}
+
+// rpcMetrics accumulates MethodStats per method
+type rpcMetrics struct {
+	mu      sync.Mutex
+	methods map[string]*methodCounters
+}
+
+type methodCounters struct {
+	calls, failures, retries uint64
+	latency                  time.Duration
+}
+
+func newRPCMetrics() *rpcMetrics {
+	return &rpcMetrics{methods: make(map[string]*methodCounters)}
+}
+
+// record adds a finished call of method that took attempts tries
+func (m *rpcMetrics) record(method string, attempts int, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.methods[method]
+	if !ok {
+		c = &methodCounters{}
+		m.methods[method] = c
+	}
+	c.calls++
+	if err != nil {
+		c.failures++
+	}
+	if attempts > 1 {
+		c.retries += uint64(attempts - 1)
+	}
+	c.latency += latency
+}
+
+// snapshot copies the counters into MethodStats
+func (m *rpcMetrics) snapshot() map[string]MethodStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := make(map[string]MethodStats, len(m.methods))
+	for method, c := range m.methods {
+		stats[method] = MethodStats{
+			Calls:          c.calls,
+			Failures:       c.failures,
+			Retries:        c.retries,
+			AverageLatency: c.latency / time.Duration(c.calls),
+		}
+	}
+	return stats
+}
+
+// Stats returns the RPC counters collected so far, keyed by the Web3Utils
+// method that made the calls (e.g. "GetBalance"). Helpers shared by several
+// methods are reported under their own lowercase names. The map is a copy
+// and is empty when metrics are disabled with WithMetrics.
+func (w *Web3Utils) Stats() map[string]MethodStats {
+	if w.metrics == nil {
+		return map[string]MethodStats{}
+	}
+	return w.metrics.snapshot()
+}
+
+// callerMethod names the function skip frames above its caller, stripped
+// of the package path, the Web3Utils receiver and closure suffixes
+func callerMethod(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "unknown"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	// Drop the package name
+	if _, rest, ok := strings.Cut(name, "."); ok {
+		name = rest
+	}
+	name = strings.TrimPrefix(name, "(*Web3Utils).")
+	name = strings.NewReplacer("(*", "", ")", "").Replace(name)
+	parts := strings.Split(name, ".")
+	for len(parts) > 1 && strings.HasPrefix(parts[len(parts)-1], "func") {
+		parts = parts[:len(parts)-1]
+	}
+	return strings.Join(parts, ".")
+}
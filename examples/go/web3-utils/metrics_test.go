@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestStats(t *testing.T) {
+	broke := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	var balanceCalls atomic.Int64
+	m := newMockRPC(t)
+	m.result("eth_blockNumber", "0x64")
+	m.handle("eth_getBalance", func(params []json.RawMessage) (interface{}, error) {
+		var addr common.Address
+		if err := json.Unmarshal(params[0], &addr); err != nil {
+			return nil, err
+		}
+		if addr == broke {
+			return nil, &rpcError{code: -32000, msg: "missing trie node"}
+		}
+		// The very first request is rate limited and retried
+		if balanceCalls.Add(1) == 1 {
+			return nil, &rpcError{code: -32005, msg: "limit exceeded"}
+		}
+		return "0x1", nil
+	})
+	w := m.dial(t, WithRetry(RetryConfig{MaxAttempts: 3, RetryCodes: DefaultRetryCodes}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := w.GetBlockNumber(context.Background()); err != nil {
+				t.Errorf("GetBlockNumber: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	for i := 0; i < 2; i++ {
+		if _, err := w.GetBalance(context.Background(), testAddress); err != nil {
+			t.Fatalf("GetBalance: %v", err)
+		}
+	}
+	if _, err := w.GetBalance(context.Background(), broke.Hex()); err == nil {
+		t.Fatal("expected GetBalance to fail")
+	}
+
+	stats := w.Stats()
+	if got := stats["GetBlockNumber"]; got.Calls != 5 || got.Failures != 0 || got.Retries != 0 {
+		t.Fatalf("GetBlockNumber stats = %+v, want 5 calls", got)
+	}
+	got := stats["GetBalance"]
+	if got.Calls != 3 || got.Failures != 1 || got.Retries != 1 {
+		t.Fatalf("GetBalance stats = %+v, want 3 calls, 1 failure, 1 retry", got)
+	}
+	if got.AverageLatency <= 0 {
+		t.Fatalf("GetBalance average latency = %v, want > 0", got.AverageLatency)
+	}
+}
+
+func TestStatsDisabled(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_blockNumber", "0x64")
+	w := m.dial(t, WithMetrics(false))
+	if _, err := w.GetBlockNumber(context.Background()); err != nil {
+		t.Fatalf("GetBlockNumber: %v", err)
+	}
+	if stats := w.Stats(); len(stats) != 0 {
+		t.Fatalf("stats = %v, want none with metrics disabled", stats)
+	}
+}
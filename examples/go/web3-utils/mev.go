@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// pendingBlockTransactions returns the transactions of the node's pending
+// block, which is the closest thing to a mempool view most providers offer
+func (w *Web3Utils) pendingBlockTransactions(ctx context.Context) ([]*types.Transaction, error) {
+	var block struct {
+		Transactions []*types.Transaction `json:"transactions"`
+	}
+	err := w.call(ctx, func(c *ethclient.Client) error {
+		return c.Client().CallContext(ctx, &block, "eth_getBlockByNumber", "pending", true)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending transactions: %w", err)
+	}
+	return block.Transactions, nil
+}
+
+// EstimateSlippageRisk returns a best-effort score in [0, 1) estimating how
+// exposed a pending swap is to sandwiching or other MEV. It looks for other
+// pending transactions sent to the same contract (router or pool) as the
+// swap, and counts those paying an equal or higher priority fee twice since
+// they would be ordered ahead of it.
+//
+// The score is only a heuristic: it sees nothing beyond the node's pending
+// block, private order flow is invisible, and a shared target contract does
+// not prove two swaps touch the same pool.
+func (w *Web3Utils) EstimateSlippageRisk(ctx context.Context, swap *types.Transaction) (float64, error) {
+	if swap.To() == nil {
+		return 0, fmt.Errorf("swap transaction has no target contract")
+	}
+
+	pending, err := w.pendingBlockTransactions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var pressure int
+	for _, tx := range pending {
+		if tx.Hash() == swap.Hash() || tx.To() == nil || *tx.To() != *swap.To() {
+			continue
+		}
+		pressure++
+		if tx.GasTipCapCmp(swap) >= 0 {
+			pressure++
+		}
+	}
+	return 1 - 1/float64(1+pressure), nil
+}
+
+// ArbBreakEvenGasPrice returns the highest gas price, in Wei, at which a
+// trade expected to earn profitWei while using gasLimit gas still breaks
+// even: profit / gas, rounded down so the cost never exceeds the profit.
+// Unprofitable trades yield zero, and a zero gas limit yields nil since any
+// price breaks even.
+func ArbBreakEvenGasPrice(profitWei *big.Int, gasLimit uint64) *big.Int {
+	if gasLimit == 0 {
+		return nil
+	}
+	if profitWei.Sign() <= 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Quo(profitWei, new(big.Int).SetUint64(gasLimit))
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var testRouter = common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+
+// dynamicTx signs an EIP-1559 transaction with a throwaway key
+func dynamicTx(t *testing.T, nonce uint64, to common.Address, tip, feeCap *big.Int) *types.Transaction {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       200000,
+		To:        &to,
+		Data:      []byte{0x38, 0xed, 0x17, 0x39},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func pendingBlock(txs ...*types.Transaction) map[string]interface{} {
+	if txs == nil {
+		txs = []*types.Transaction{}
+	}
+	return map[string]interface{}{"transactions": txs}
+}
+
+func TestEstimateSlippageRisk(t *testing.T) {
+	swap := dynamicTx(t, 0, testRouter, gwei(2), gwei(50))
+	other := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+	quiet := newMockRPC(t)
+	quiet.result("eth_getBlockByNumber", pendingBlock(swap, dynamicTx(t, 0, other, gwei(5), gwei(50))))
+	low, err := quiet.dial(t).EstimateSlippageRisk(context.Background(), swap)
+	if err != nil {
+		t.Fatalf("EstimateSlippageRisk: %v", err)
+	}
+	if low != 0 {
+		t.Fatalf("risk without competing swaps = %v, want 0", low)
+	}
+
+	busy := newMockRPC(t)
+	busy.result("eth_getBlockByNumber", pendingBlock(
+		swap,
+		dynamicTx(t, 0, testRouter, gwei(3), gwei(50)),
+		dynamicTx(t, 0, testRouter, gwei(1), gwei(50)),
+	))
+	high, err := busy.dial(t).EstimateSlippageRisk(context.Background(), swap)
+	if err != nil {
+		t.Fatalf("EstimateSlippageRisk: %v", err)
+	}
+	if high <= low || high >= 1 {
+		t.Fatalf("risk with competing swaps = %v, want in (%v, 1)", high, low)
+	}
+}
+
+func TestArbBreakEvenGasPrice(t *testing.T) {
+	// 0.01 ETH profit over 200k gas breaks even at 50 gwei
+	if got := ArbBreakEvenGasPrice(gwei(10_000_000), 200_000); got.Cmp(gwei(50)) != 0 {
+		t.Fatalf("break-even = %s, want 50 gwei", got)
+	}
+	// Rounds down: 1000 wei over 3 gas is 333 wei, as 334 would lose money
+	if got := ArbBreakEvenGasPrice(big.NewInt(1000), 3); got.Int64() != 333 {
+		t.Fatalf("break-even = %s, want 333", got)
+	}
+	if got := ArbBreakEvenGasPrice(big.NewInt(-5), 21000); got.Sign() != 0 {
+		t.Fatalf("break-even of a loss = %s, want 0", got)
+	}
+	if got := ArbBreakEvenGasPrice(big.NewInt(1000), 0); got != nil {
+		t.Fatalf("break-even with no gas = %s, want nil", got)
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// rpcHandler answers a single JSON-RPC method call in the mock server
+type rpcHandler func(params []json.RawMessage) (interface{}, error)
+
+// rpcError lets handlers return a JSON-RPC error with a specific code
+type rpcError struct {
+	code int
+	msg  string
+	data interface{}
+}
+
+func (e *rpcError) Error() string { return e.msg }
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcErrorBody   `json:"error,omitempty"`
+}
+
+type rpcErrorBody struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// mockRPC is a minimal JSON-RPC HTTP server for exercising Web3Utils
+type mockRPC struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]rpcHandler
+	calls    map[string]int
+	params   map[string][][]json.RawMessage
+	batches  []int
+	// httpFailures are HTTP status codes answered, in order, to the next
+	// requests instead of dispatching them
+	httpFailures []int
+}
+
+func newMockRPC(t *testing.T) *mockRPC {
+	t.Helper()
+	m := &mockRPC{
+		handlers: make(map[string]rpcHandler),
+		calls:    make(map[string]int),
+		params:   make(map[string][][]json.RawMessage),
+	}
+	m.server = httptest.NewServer(http.HandlerFunc(m.serveHTTP))
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+// handle registers the handler for an RPC method
+func (m *mockRPC) handle(method string, h rpcHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers[method] = h
+}
+
+// result registers a handler that always returns the given value
+func (m *mockRPC) result(method string, v interface{}) {
+	m.handle(method, func([]json.RawMessage) (interface{}, error) { return v, nil })
+}
+
+// failHTTP makes the next n requests fail with the given HTTP status
+func (m *mockRPC) failHTTP(status, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := 0; i < n; i++ {
+		m.httpFailures = append(m.httpFailures, status)
+	}
+}
+
+// callCount reports how many times a method has been called
+func (m *mockRPC) callCount(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+
+// callParams returns the raw params of every call made to a method
+func (m *mockRPC) callParams(method string) [][]json.RawMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([][]json.RawMessage(nil), m.params[method]...)
+}
+
+// batchSizes returns the size of every batch request received
+func (m *mockRPC) batchSizes() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]int(nil), m.batches...)
+}
+
+// dial connects a Web3Utils instance to the mock server
+func (m *mockRPC) dial(t *testing.T, opts ...Option) *Web3Utils {
+	t.Helper()
+	w, err := NewWeb3Utils(m.server.URL, opts...)
+	if err != nil {
+		t.Fatalf("NewWeb3Utils: %v", err)
+	}
+	t.Cleanup(w.Close)
+	return w
+}
+
+func (m *mockRPC) serveHTTP(rw http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	if len(m.httpFailures) > 0 {
+		status := m.httpFailures[0]
+		m.httpFailures = m.httpFailures[1:]
+		m.mu.Unlock()
+		http.Error(rw, http.StatusText(status), status)
+		return
+	}
+	m.mu.Unlock()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+
+	if len(body) > 0 && body[0] == '[' {
+		var reqs []rpcRequest
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		m.mu.Lock()
+		m.batches = append(m.batches, len(reqs))
+		m.mu.Unlock()
+		resps := make([]rpcResponse, len(reqs))
+		for i, req := range reqs {
+			resps[i] = m.dispatch(req)
+		}
+		json.NewEncoder(rw).Encode(resps)
+		return
+	}
+
+	var req rpcRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(rw).Encode(m.dispatch(req))
+}
+
+func (m *mockRPC) dispatch(req rpcRequest) rpcResponse {
+	m.mu.Lock()
+	m.calls[req.Method]++
+	m.params[req.Method] = append(m.params[req.Method], req.Params)
+	h, ok := m.handlers[req.Method]
+	m.mu.Unlock()
+
+	resp := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+	if !ok {
+		resp.Error = &rpcErrorBody{Code: -32601, Message: fmt.Sprintf("the method %s does not exist/is not available", req.Method)}
+		return resp
+	}
+	result, err := h(req.Params)
+	if err != nil {
+		resp.Error = &rpcErrorBody{Code: -32000, Message: err.Error()}
+		if re, ok := err.(*rpcError); ok {
+			resp.Error.Code = re.code
+			resp.Error.Data = re.data
+		}
+		return resp
+	}
+	if result == nil {
+		result = json.RawMessage("null")
+	}
+	resp.Result = result
+	return resp
+}
+
+// mockReceipt builds a receipt that round-trips through JSON
+func mockReceipt(txHash string, block int64, status uint64) *types.Receipt {
+	return &types.Receipt{
+		Status:      status,
+		TxHash:      common.HexToHash(txHash),
+		BlockNumber: big.NewInt(block),
+		Logs:        []*types.Log{},
+	}
+}
+
+// mockBlock renders a block as eth_getBlockByNumber would return it with full
+// transactions, filling in the header fields ethclient validates
+func mockBlock(header *types.Header, txs ...*types.Transaction) map[string]interface{} {
+	h := types.CopyHeader(header)
+	if h.Difficulty == nil {
+		h.Difficulty = new(big.Int)
+	}
+	if h.Number == nil {
+		h.Number = new(big.Int)
+	}
+	h.UncleHash = types.EmptyUncleHash
+	h.TxHash = types.EmptyTxsHash
+	if len(txs) > 0 {
+		h.TxHash = common.Hash{0x01}
+	}
+
+	raw, err := json.Marshal(h)
+	if err != nil {
+		panic(err)
+	}
+	var block map[string]interface{}
+	if err := json.Unmarshal(raw, &block); err != nil {
+		panic(err)
+	}
+	if txs == nil {
+		txs = []*types.Transaction{}
+	}
+	block["transactions"] = txs
+	block["uncles"] = []common.Hash{}
+	return block
+}
+
+// blockTag decodes the block number parameter of a by-number RPC call
+func blockTag(param json.RawMessage) (uint64, bool) {
+	var tag string
+	if err := json.Unmarshal(param, &tag); err != nil {
+		return 0, false
+	}
+	n, ok := new(big.Int).SetString(strings.TrimPrefix(tag, "0x"), 16)
+	if !ok {
+		return 0, false
+	}
+	return n.Uint64(), true
+}
+
+// mockNonces serves the latest and pending nonce of an account
+func mockNonces(m *mockRPC, latest, pending uint64) {
+	m.handle("eth_getTransactionCount", func(params []json.RawMessage) (interface{}, error) {
+		var tag string
+		json.Unmarshal(params[1], &tag)
+		if tag == "pending" {
+			return hexutil.Uint64(pending), nil
+		}
+		return hexutil.Uint64(latest), nil
+	})
+}
+
+// rpcTxJSON renders a transaction as returned by eth_getTransactionByHash,
+// merging extra fields such as blockNumber into the object
+func rpcTxJSON(t *testing.T, tx *types.Transaction, extra map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range extra {
+		obj[k] = v
+	}
+	return obj
+}
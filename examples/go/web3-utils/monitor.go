@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultUnhealthyThreshold is the number of consecutive polling failures
+// after which a BlockMonitor reports the endpoint as unhealthy
+const DefaultUnhealthyThreshold = 3
+
+// BlockStatus is the result of a single BlockMonitor poll
+type BlockStatus struct {
+	// Block is the latest block number seen so far
+	Block uint64
+	// Healthy is false once failures reach the unhealthy threshold
+	Healthy bool
+	// ConsecutiveFailures counts failed polls since the last success
+	ConsecutiveFailures int
+	// Err is the error of this poll, if it failed
+	Err error
+}
+
+// BlockMonitor polls the latest block number and tracks the health of the
+// endpoint for liveness dashboards. A failed poll does not stop the monitor:
+// it keeps polling, and the underlying RPC client reconnects on its own, so
+// the monitor becomes healthy again as soon as a poll succeeds.
+type BlockMonitor struct {
+	utils     *Web3Utils
+	interval  time.Duration
+	threshold int
+
+	mu       sync.RWMutex
+	latest   uint64
+	failures int
+}
+
+// NewBlockMonitor creates a monitor polling every interval that turns
+// unhealthy after unhealthyThreshold consecutive failures
+func NewBlockMonitor(utils *Web3Utils, interval time.Duration, unhealthyThreshold int) *BlockMonitor {
+	if unhealthyThreshold < 1 {
+		unhealthyThreshold = DefaultUnhealthyThreshold
+	}
+	return &BlockMonitor{utils: utils, interval: interval, threshold: unhealthyThreshold}
+}
+
+// Run polls until ctx is cancelled or the Web3Utils is shut down, emitting a
+// BlockStatus after every poll. The channel is closed when the monitor stops.
+func (m *BlockMonitor) Run(ctx context.Context) <-chan BlockStatus {
+	out := make(chan BlockStatus)
+	ctx, done, err := m.utils.watch(ctx)
+	if err != nil {
+		close(out)
+		return out
+	}
+	go func() {
+		defer done()
+		defer close(out)
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case out <- m.poll(ctx):
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return out
+}
+
+// Healthy reports whether the endpoint is below the failure threshold
+func (m *BlockMonitor) Healthy() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.failures < m.threshold
+}
+
+// Latest returns the latest block number seen by the monitor
+func (m *BlockMonitor) Latest() uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.latest
+}
+
+// poll fetches the block number once and updates the health state. Calls
+// aborted because ctx was cancelled say nothing about the endpoint and do
+// not count as failures.
+func (m *BlockMonitor) poll(ctx context.Context) BlockStatus {
+	block, err := m.utils.GetBlockNumber(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		if ctx.Err() == nil {
+			m.failures++
+		}
+	} else {
+		m.failures = 0
+		if block > m.latest {
+			m.latest = block
+		}
+	}
+	return BlockStatus{
+		Block:               m.latest,
+		Healthy:             m.failures < m.threshold,
+		ConsecutiveFailures: m.failures,
+		Err:                 err,
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBlockMonitorHealthTransitions(t *testing.T) {
+	// nil entries fail, others return that block number
+	script := []interface{}{"0x64", nil, nil, nil, "0x66", nil}
+	step := 0
+	m := newMockRPC(t)
+	m.handle("eth_blockNumber", func([]json.RawMessage) (interface{}, error) {
+		r := script[step%len(script)]
+		step++
+		if r == nil {
+			return nil, errors.New("upstream unavailable")
+		}
+		return r, nil
+	})
+
+	monitor := NewBlockMonitor(m.dial(t), time.Millisecond, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	statuses := monitor.Run(ctx)
+
+	want := []struct {
+		block    uint64
+		healthy  bool
+		failures int
+	}{
+		{100, true, 0},
+		{100, true, 1},
+		{100, false, 2},
+		{100, false, 3},
+		{102, true, 0},
+		{102, true, 1},
+	}
+	for i, w := range want {
+		s := <-statuses
+		if s.Block != w.block || s.Healthy != w.healthy || s.ConsecutiveFailures != w.failures {
+			t.Fatalf("poll %d = %+v, want block %d healthy %v failures %d", i, s, w.block, w.healthy, w.failures)
+		}
+		if (s.Err != nil) != (w.failures > 0) {
+			t.Fatalf("poll %d err = %v", i, s.Err)
+		}
+	}
+	cancel()
+	for range statuses {
+	}
+
+	if !monitor.Healthy() || monitor.Latest() != 102 {
+		t.Fatalf("final state healthy=%v latest=%d", monitor.Healthy(), monitor.Latest())
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Multicall3 is the address of the Multicall3 contract, deployed at the same
+// address on most EVM chains
+var Multicall3 = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+const multicall3ABI = `[{"type":"function","name":"aggregate3","stateMutability":"payable",
+	"inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],
+	"outputs":[{"name":"returnData","type":"tuple[]","components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]}]`
+
+var multicall3 = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// multicallCall is one call of a Multicall3 aggregate3 batch
+type multicallCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// multicallResult is the outcome of one call of an aggregate3 batch
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// multicall executes calls in a single eth_call through Multicall3. Calls
+// are allowed to fail individually; check each result's Success.
+func (w *Web3Utils) multicall(ctx context.Context, calls []multicallCall) ([]multicallResult, error) {
+	for i := range calls {
+		calls[i].AllowFailure = true
+	}
+	data, err := multicall3.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode multicall: %w", err)
+	}
+	out, err := w.callContract(ctx, Multicall3, data)
+	if err != nil {
+		return nil, fmt.Errorf("multicall failed: %w", err)
+	}
+	values, err := multicall3.Unpack("aggregate3", out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode multicall result: %w", err)
+	}
+	results := *abi.ConvertType(values[0], new([]multicallResult)).(*[]multicallResult)
+	if len(results) != len(calls) {
+		return nil, fmt.Errorf("multicall returned %d results for %d calls", len(results), len(calls))
+	}
+	return results, nil
+}
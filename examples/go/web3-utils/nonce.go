@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// NonceManager hands out transaction nonces from a local counter per
+// address, so transactions sent in quick succession do not reuse the stale
+// pending nonce reported by the node. SendETH uses one unless disabled with
+// WithNonceManager. It is safe for concurrent use.
+type NonceManager struct {
+	utils *Web3Utils
+
+	mu       sync.Mutex
+	accounts map[common.Address]*accountNonce
+}
+
+// accountNonce is the local nonce counter of one address
+type accountNonce struct {
+	mu     sync.Mutex
+	next   uint64
+	synced bool
+
+	// sendMu serializes Send, so transactions reach the node in nonce order
+	sendMu sync.Mutex
+}
+
+// NewNonceManager creates a nonce manager reading initial nonces through utils
+func NewNonceManager(utils *Web3Utils) *NonceManager {
+	return &NonceManager{utils: utils, accounts: make(map[common.Address]*accountNonce)}
+}
+
+// account returns the counter of address, creating it on first use
+func (n *NonceManager) account(address common.Address) *accountNonce {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	a, ok := n.accounts[address]
+	if !ok {
+		a = &accountNonce{}
+		n.accounts[address] = a
+	}
+	return a
+}
+
+// Next returns the nonce for the next transaction from address and advances
+// the local counter. The counter starts at the node's pending nonce on first
+// use and after a Reset; calls for the same address are serialized, so
+// concurrent callers get distinct sequential nonces.
+func (n *NonceManager) Next(ctx context.Context, address common.Address) (uint64, error) {
+	a := n.account(address)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.synced {
+		var pending uint64
+		err := n.utils.call(ctx, func(c *ethclient.Client) (err error) {
+			pending, err = c.PendingNonceAt(ctx, address)
+			return err
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to get pending nonce: %w", err)
+		}
+		a.next, a.synced = pending, true
+	}
+	nonce := a.next
+	a.next++
+	return nonce, nil
+}
+
+// Send calls send with the nonce from Next. If send fails the address is
+// Reset, so the next call resyncs from the node rather than leaving a gap.
+// Sends from the same address are serialized, so they reach the node in
+// nonce order.
+func (n *NonceManager) Send(ctx context.Context, address common.Address, send func(nonce uint64) error) error {
+	a := n.account(address)
+	a.sendMu.Lock()
+	defer a.sendMu.Unlock()
+
+	nonce, err := n.Next(ctx, address)
+	if err != nil {
+		return err
+	}
+	if err := send(nonce); err != nil {
+		n.Reset(address)
+		return err
+	}
+	return nil
+}
+
+// Reset drops the local counter of address so the next call to Next resyncs
+// it from the node, e.g. after sending from the address by other means
+func (n *NonceManager) Reset(address common.Address) {
+	a := n.account(address)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.synced = false
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestNonceManagerConcurrentNext(t *testing.T) {
+	m := newMockRPC(t)
+	mockNonces(m, 3, 5)
+	nonces := NewNonceManager(m.dial(t))
+	from := common.HexToAddress(testAddress)
+
+	const callers = 10
+	got := make([]uint64, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i], errs[i] = nonces.Next(context.Background(), from)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+	}
+
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	for i, n := range got {
+		if n != uint64(5+i) {
+			t.Fatalf("nonces = %v, want 5..14", got)
+		}
+	}
+	if calls := m.callCount("eth_getTransactionCount"); calls != 1 {
+		t.Fatalf("fetched pending nonce %d times, want 1", calls)
+	}
+}
+
+func TestNonceManagerReset(t *testing.T) {
+	m := newMockRPC(t)
+	mockNonces(m, 5, 5)
+	nonces := NewNonceManager(m.dial(t))
+	from := common.HexToAddress(testAddress)
+	ctx := context.Background()
+
+	next := func(want uint64) {
+		t.Helper()
+		got, err := nonces.Next(ctx, from)
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		if got != want {
+			t.Fatalf("Next = %d, want %d", got, want)
+		}
+	}
+	next(5)
+	next(6)
+	mockNonces(m, 9, 9)
+	next(7)
+	nonces.Reset(from)
+	next(9)
+}
+
+func TestSendETHConcurrentNonces(t *testing.T) {
+	m := newMockRPC(t)
+	ledger := newMockLedger(m, gwei(10))
+	key, _ := crypto.GenerateKey()
+	sender := PrivateKeyToAddress(key)
+	ledger.balances[sender] = eth(100)
+	ledger.nonces[sender] = 5
+	w := m.dial(t)
+	to := common.HexToAddress(testAddress)
+
+	const sends = 10
+	errs := make([]error, sends)
+	var wg sync.WaitGroup
+	for i := 0; i < sends; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = w.SendETH(context.Background(), key, to, eth(1))
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("SendETH: %v", err)
+		}
+	}
+
+	var nonces []uint64
+	for _, params := range m.callParams("eth_sendRawTransaction") {
+		var raw hexutil.Bytes
+		json.Unmarshal(params[0], &raw)
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			t.Fatal(err)
+		}
+		nonces = append(nonces, tx.Nonce())
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	if len(nonces) != sends {
+		t.Fatalf("broadcast %d txs, want %d", len(nonces), sends)
+	}
+	for i, n := range nonces {
+		if n != uint64(5+i) {
+			t.Fatalf("nonces = %v, want 5..14", nonces)
+		}
+	}
+	if calls := m.callCount("eth_getTransactionCount"); calls != 1 {
+		t.Fatalf("fetched pending nonce %d times, want 1", calls)
+	}
+}
+
+func TestNonceManagerResetsOnFailedSend(t *testing.T) {
+	m := newMockRPC(t)
+	mockNonces(m, 5, 5)
+	nonces := NewNonceManager(m.dial(t))
+	from := common.HexToAddress(testAddress)
+	ctx := context.Background()
+
+	use := func(want uint64, err error) {
+		t.Helper()
+		var got uint64
+		if e := nonces.Send(ctx, from, func(n uint64) error { got = n; return err }); !errors.Is(e, err) {
+			t.Fatalf("Send err = %v, want %v", e, err)
+		}
+		if got != want {
+			t.Fatalf("Send used nonce %d, want %d", got, want)
+		}
+	}
+	use(5, nil)
+	use(6, nil)
+
+	// A failed send does not advance the counter and resyncs from the node
+	errRejected := errors.New("rejected")
+	use(7, errRejected)
+	mockNonces(m, 7, 7)
+	use(7, nil)
+	if calls := m.callCount("eth_getTransactionCount"); calls != 2 {
+		t.Fatalf("fetched pending nonce %d times, want 2", calls)
+	}
+
+	nonces.Reset(from)
+	mockNonces(m, 9, 9)
+	use(9, nil)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"math/big"
+	"time"
+)
+
+// Option configures optional Web3Utils behavior
+type Option func(*Web3Utils)
+
+// WithErrorOnZeroBalance makes GetBalance return ErrZeroBalance instead of
+// silently returning 0 for accounts that hold no ETH. Disabled by default.
+func WithErrorOnZeroBalance(enabled bool) Option {
+	return func(w *Web3Utils) {
+		w.errorOnZeroBalance = enabled
+	}
+}
+
+// WithGasPriceHistory records every price returned by GetGasPrice into h so
+// it can later be analyzed, e.g. with CheapestWindow
+func WithGasPriceHistory(h *GasPriceHistory) Option {
+	return func(w *Web3Utils) {
+		w.gasHistory = h
+	}
+}
+
+// WithRetry enables retrying of RPC calls that fail transiently, backing off
+// exponentially between attempts; see RetryConfig. Calls are not retried by
+// default.
+func WithRetry(cfg RetryConfig) Option {
+	return func(w *Web3Utils) {
+		w.retry = cfg
+	}
+}
+
+// WithPollInterval sets how often block watchers poll the node
+func WithPollInterval(d time.Duration) Option {
+	return func(w *Web3Utils) {
+		w.settings.pollInterval = d
+		w.settings.explicit.pollInterval = true
+	}
+}
+
+// WithConfirmationTarget sets the confirmation count at which
+// ConfirmationStream completes
+func WithConfirmationTarget(n uint64) Option {
+	return func(w *Web3Utils) {
+		w.settings.confirmationTarget = n
+		w.settings.explicit.confirmationTarget = true
+	}
+}
+
+// WithGasEstimateFallback makes EstimateGas return limit when the node
+// cannot estimate a call instead of failing outright. Some nodes reject
+// estimates for state-dependent calls. Each fallback is reported to a
+// WarningLogger. Reverts, cancellation and Shutdown are still errors.
+// Disabled by default.
+func WithGasEstimateFallback(limit uint64) Option {
+	return func(w *Web3Utils) {
+		w.gasFallback = limit
+	}
+}
+
+// WithBatching enables transparent auto-batching of HTTP JSON-RPC calls: calls
+// issued within window of each other are sent to the node as one batch
+// request. A zero window disables batching, which is the default.
+func WithBatching(window time.Duration) Option {
+	return func(w *Web3Utils) {
+		w.batchWindow = window
+	}
+}
+
+// WithENSCache configures the ENS resolution cache: at most size names are
+// kept, resolved names for ttl and unregistered names for negativeTTL. A
+// size of 0 disables caching.
+func WithENSCache(size int, ttl, negativeTTL time.Duration) Option {
+	return func(w *Web3Utils) {
+		w.ensCache = newENSCache(size, ttl, negativeTTL)
+	}
+}
+
+// WithMinTipFloor makes SuggestGasFees never suggest a priority fee below
+// floor. Some nodes suggest a zero tip during quiet periods, which can leave
+// transactions stuck behind a validator's minimum. No floor by default.
+func WithMinTipFloor(floor *big.Int) Option {
+	return func(w *Web3Utils) {
+		w.settings.minTip = floor
+		w.settings.explicit.minTip = true
+	}
+}
+
+// WithBaseFeeBuffer sets how far above the latest base fee, in percent,
+// SuggestGasFees sets the max fee. Defaults to DefaultBaseFeeBufferPercent or
+// the connected chain's profile.
+func WithBaseFeeBuffer(percent uint64) Option {
+	return func(w *Web3Utils) {
+		w.settings.baseFeeBuffer = percent
+		w.settings.explicit.baseFeeBuffer = true
+	}
+}
+
+// WithBeaconConfig sets the beacon chain parameters used by SlotAndEpoch.
+// Defaults to MainnetBeaconConfig.
+func WithBeaconConfig(cfg BeaconConfig) Option {
+	return func(w *Web3Utils) {
+		w.beacon = cfg
+	}
+}
+
+// WithL2FeeConfig sets the rollup fee parameters used by L2DataCost.
+// Defaults to OptimismL2FeeConfig.
+func WithL2FeeConfig(cfg L2FeeConfig) Option {
+	return func(w *Web3Utils) {
+		w.l2Fees = cfg
+	}
+}
+
+// WithMetrics enables or disables the per-method RPC counters reported by
+// Stats. Enabled by default.
+func WithMetrics(enabled bool) Option {
+	return func(w *Web3Utils) {
+		if !enabled {
+			w.metrics = nil
+		} else if w.metrics == nil {
+			w.metrics = newRPCMetrics()
+		}
+	}
+}
+
+// WithLogger reports every RPC call to logger. A nil logger disables
+// logging, which is the default.
+func WithLogger(logger Logger) Option {
+	return func(w *Web3Utils) {
+		w.logger = logger
+	}
+}
+
+// WithLogChunkSize sets the largest block range requested in a single
+// eth_getLogs call; wider scans are split into chunks of this many blocks.
+// Defaults to DefaultLogChunkSize; zero is ignored.
+func WithLogChunkSize(blocks uint64) Option {
+	return func(w *Web3Utils) {
+		if blocks > 0 {
+			w.logChunkSize = blocks
+		}
+	}
+}
+
+// WithBlockTimeEstimator sets the source of the average block time used by
+// AverageBlockTime and the features built on it, for chains where sampling
+// recent headers misleads, such as L2s that only produce blocks on demand.
+// A nil estimator restores the default header sampling.
+func WithBlockTimeEstimator(e BlockTimeEstimator) Option {
+	return func(w *Web3Utils) {
+		w.blockTime = e
+	}
+}
+
+// WithNonceManager enables or disables the local nonce counter SendETH takes
+// nonces from. Enabled by default; disable it when the same accounts also
+// send through other clients, so every send reads the node's pending nonce.
+func WithNonceManager(enabled bool) Option {
+	return func(w *Web3Utils) {
+		if !enabled {
+			w.nonces = nil
+		} else if w.nonces == nil {
+			w.nonces = NewNonceManager(w)
+		}
+	}
+}
+
+// WithDustMinSenders sets how many distinct senders of tiny amounts DetectDust
+// requires before flagging them as dusting. Defaults to
+// DefaultDustMinSenders; values below 1 are ignored.
+func WithDustMinSenders(senders int) Option {
+	return func(w *Web3Utils) {
+		if senders > 0 {
+			w.dustMinSenders = senders
+		}
+	}
+}
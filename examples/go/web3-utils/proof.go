@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// AccountProof is the Merkle proof of an account and some of its storage
+// slots, as returned by eth_getProof (EIP-1186). AccountProof holds the trie
+// nodes from the state root to the account; each StorageProof holds the nodes
+// from StorageHash to the slot.
+type AccountProof struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []StorageProof  `json:"storageProof"`
+}
+
+// StorageProof is the Merkle proof of a single storage slot
+type StorageProof struct {
+	Key   common.Hash     `json:"key"`
+	Value *hexutil.Big    `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// GetProof returns the account and storage proof of address for the given
+// slots at blockNumber (nil for latest), which can be verified against the
+// state root of that block's header
+func (w *Web3Utils) GetProof(ctx context.Context, address string, slots []common.Hash, blockNumber *big.Int) (*AccountProof, error) {
+	if slots == nil {
+		slots = []common.Hash{}
+	}
+	var proof *AccountProof
+	err := w.call(ctx, func(c *ethclient.Client) error {
+		return c.Client().CallContext(ctx, &proof, "eth_getProof", common.HexToAddress(address), slots, blockArg(blockNumber))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proof: %w", err)
+	}
+	if proof == nil {
+		return nil, fmt.Errorf("no proof returned for %s", address)
+	}
+	return proof, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestGetProof(t *testing.T) {
+	slot := common.HexToHash("0x1")
+	m := newMockRPC(t)
+	m.result("eth_getProof", map[string]interface{}{
+		"address":      testAddress,
+		"accountProof": []string{"0xf90211a0", "0xf871"},
+		"balance":      "0x1bc16d674ec80000",
+		"codeHash":     "0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		"nonce":        "0x5",
+		"storageHash":  "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421",
+		"storageProof": []map[string]interface{}{
+			{"key": slot.Hex(), "value": "0x2a", "proof": []string{"0xe3a1"}},
+		},
+	})
+
+	proof, err := m.dial(t).GetProof(context.Background(), testAddress, []common.Hash{slot}, big.NewInt(100))
+	if err != nil {
+		t.Fatalf("GetProof: %v", err)
+	}
+	if proof.Address != common.HexToAddress(testAddress) {
+		t.Fatalf("address = %s", proof.Address.Hex())
+	}
+	if len(proof.AccountProof) != 2 || proof.AccountProof[1][0] != 0xf8 {
+		t.Fatalf("account proof = %v", proof.AccountProof)
+	}
+	if proof.Balance.ToInt().Cmp(new(big.Int).Mul(big.NewInt(2), big.NewInt(1e18))) != 0 || uint64(proof.Nonce) != 5 {
+		t.Fatalf("balance = %v nonce = %d", proof.Balance, proof.Nonce)
+	}
+	if proof.CodeHash != common.HexToHash("0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470") {
+		t.Fatalf("code hash = %s", proof.CodeHash.Hex())
+	}
+	if len(proof.StorageProof) != 1 {
+		t.Fatalf("storage proofs = %d, want 1", len(proof.StorageProof))
+	}
+	sp := proof.StorageProof[0]
+	if sp.Key != slot || sp.Value.ToInt().Int64() != 42 || len(sp.Proof) != 1 {
+		t.Fatalf("storage proof = %+v", sp)
+	}
+
+	params := m.callParams("eth_getProof")[0]
+	var block string
+	json.Unmarshal(params[2], &block)
+	if block != "0x64" {
+		t.Fatalf("block param = %s, want 0x64", block)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultRetryCodes are JSON-RPC error codes that usually signal a transient
+// provider problem: -32005 (limit exceeded) and -32603 (internal error)
+var DefaultRetryCodes = []int{-32005, -32603}
+
+// RetryConfig controls how failed RPC calls are retried. Only transient
+// failures are retried: network timeouts, HTTP 429 and 5xx responses, and
+// JSON-RPC errors carrying one of RetryCodes. Deterministic failures such as
+// "execution reverted" or "nonce too low" are returned immediately.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts int
+	// BaseDelay is the pause before the first retry. It doubles with every
+	// further attempt and is jittered so clients do not retry in lockstep.
+	BaseDelay time.Duration
+	// MaxDelay caps the pause between attempts; zero means no cap
+	MaxDelay time.Duration
+	// RetryCodes lists additional JSON-RPC error codes worth retrying
+	RetryCodes []int
+}
+
+// retryable reports whether err is a transient failure worth retrying
+func (c RetryConfig) retryable(err error) bool {
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	for _, code := range c.RetryCodes {
+		if rpcErr.ErrorCode() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the pause before the given retry, counting from 1: half of
+// BaseDelay * 2^(retry-1), capped at MaxDelay, plus a random part up to the
+// other half
+func (c RetryConfig) backoff(retry int) time.Duration {
+	delay := c.BaseDelay
+	for i := 1; i < retry && (c.MaxDelay <= 0 || delay < c.MaxDelay); i++ {
+		delay *= 2
+	}
+	if c.MaxDelay > 0 && delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// call runs fn against the client, retrying according to the retry config
+// and failing over between endpoints. Calls are tracked so Shutdown can wait
+// for them, recorded in Stats under the calling method and reported to the
+// Logger.
+func (w *Web3Utils) call(ctx context.Context, fn func(c *ethclient.Client) error) error {
+	if err := w.life.acquire(); err != nil {
+		return err
+	}
+	defer w.life.release()
+	if w.metrics == nil && w.logger == nil {
+		return w.callWithFailover(ctx, fn)
+	}
+
+	method := callerMethod(1)
+	attempts := 0
+	start := time.Now()
+	err := w.callWithFailover(ctx, func(c *ethclient.Client) error {
+		attempts++
+		return fn(c)
+	})
+	elapsed := time.Since(start)
+	if w.metrics != nil {
+		w.metrics.record(method, attempts, elapsed, err)
+	}
+	if w.logger != nil {
+		w.logger.LogCall(method, elapsed, err)
+	}
+	return err
+}
+
+// retryCall runs fn against c, retrying according to the retry config
+func (w *Web3Utils) retryCall(ctx context.Context, c *ethclient.Client, fn func(c *ethclient.Client) error) error {
+	attempts := w.retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn(c)
+		if err == nil || attempt >= attempts || ctx.Err() != nil || !w.retry.retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.retry.backoff(attempt)):
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestRetrySkipsNonRetryableCode(t *testing.T) {
+	m := newMockRPC(t)
+	m.handle("eth_getBalance", func([]json.RawMessage) (interface{}, error) {
+		return nil, &rpcError{code: -32000, msg: "nonce too low"}
+	})
+
+	w := m.dial(t, WithRetry(RetryConfig{MaxAttempts: 3, RetryCodes: DefaultRetryCodes}))
+	if _, err := w.GetBalance(context.Background(), testAddress); err == nil {
+		t.Fatal("expected error")
+	}
+	if n := m.callCount("eth_getBalance"); n != 1 {
+		t.Fatalf("eth_getBalance called %d times, want 1", n)
+	}
+}
+
+func TestRetryOnConfiguredCode(t *testing.T) {
+	m := newMockRPC(t)
+	failures := 2
+	m.handle("eth_getBalance", func([]json.RawMessage) (interface{}, error) {
+		if failures > 0 {
+			failures--
+			return nil, &rpcError{code: -32005, msg: "limit exceeded"}
+		}
+		return "0x1", nil
+	})
+
+	w := m.dial(t, WithRetry(RetryConfig{MaxAttempts: 3, RetryCodes: DefaultRetryCodes}))
+	balance, err := w.GetBalance(context.Background(), testAddress)
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	if balance.Int64() != 1 {
+		t.Fatalf("balance = %s, want 1", balance)
+	}
+	if n := m.callCount("eth_getBalance"); n != 3 {
+		t.Fatalf("eth_getBalance called %d times, want 3", n)
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	m := newMockRPC(t)
+	m.handle("eth_blockNumber", func([]json.RawMessage) (interface{}, error) {
+		return nil, &rpcError{code: -32603, msg: "internal error"}
+	})
+
+	w := m.dial(t, WithRetry(RetryConfig{MaxAttempts: 2, RetryCodes: DefaultRetryCodes}))
+	if _, err := w.GetBlockNumber(context.Background()); err == nil {
+		t.Fatal("expected error after exhausting retries")
+	}
+	if n := m.callCount("eth_blockNumber"); n != 2 {
+		t.Fatalf("eth_blockNumber called %d times, want 2", n)
+	}
+}
+
+func TestRetryBacksOffOnRateLimit(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_getBalance", "0x1")
+	w := m.dial(t, WithRetry(RetryConfig{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond, MaxDelay: time.Second}))
+
+	m.failHTTP(http.StatusTooManyRequests, 2)
+	start := time.Now()
+	balance, err := w.GetBalance(context.Background(), testAddress)
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	if balance.Int64() != 1 {
+		t.Fatalf("balance = %s, want 1", balance)
+	}
+	m.mu.Lock()
+	pending := len(m.httpFailures)
+	m.mu.Unlock()
+	if pending != 0 || m.callCount("eth_getBalance") != 1 {
+		t.Fatalf("expected two rate-limited attempts then one served, %d failures left", pending)
+	}
+	// Two retries, pausing at least 5ms and then 10ms
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Fatalf("retried after %v, expected backoff", elapsed)
+	}
+}
+
+func TestRetryTransientClassification(t *testing.T) {
+	cfg := RetryConfig{RetryCodes: DefaultRetryCodes}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", rpc.HTTPError{StatusCode: http.StatusTooManyRequests}, true},
+		{"bad gateway", rpc.HTTPError{StatusCode: http.StatusBadGateway}, true},
+		{"bad request", rpc.HTTPError{StatusCode: http.StatusBadRequest}, false},
+		{"timeout", &url.Error{Op: "Post", URL: "http://node", Err: timeoutError{}}, true},
+		{"reverted", &jsonRPCError{code: 3, msg: "execution reverted"}, false},
+		{"limit exceeded", &jsonRPCError{code: -32005, msg: "limit exceeded"}, true},
+	}
+	for _, tt := range tests {
+		if got := cfg.retryable(tt.err); got != tt.want {
+			t.Errorf("%s: retryable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryBackoffCapped(t *testing.T) {
+	cfg := RetryConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for retry, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: 300 * time.Millisecond} {
+		for i := 0; i < 20; i++ {
+			if d := cfg.backoff(retry); d < max/2 || d > max {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", retry, d, max/2, max)
+			}
+		}
+	}
+}
+
+// timeoutError is a net.Error reporting a timeout
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// jsonRPCError is an rpc.Error with a fixed code
+type jsonRPCError struct {
+	code int
+	msg  string
+}
+
+func (e *jsonRPCError) Error() string  { return e.msg }
+func (e *jsonRPCError) ErrorCode() int { return e.code }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// errorSelector is the selector of Solidity's Error(string)
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+	// panicSelector is the selector of Solidity's Panic(uint256)
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+)
+
+// ErrNoRevertData is returned when revert data is too short to carry a selector
+var ErrNoRevertData = errors.New("no revert data")
+
+// DecodeRevertReason decodes the return data of a reverted call into a
+// human-readable reason. Error(string) yields the message, Panic(uint256)
+// yields "panic: " plus the mapped panic code, and any other selector is
+// reported as a custom error carrying the raw selector.
+func DecodeRevertReason(data []byte) (string, error) {
+	if len(data) < 4 {
+		return "", ErrNoRevertData
+	}
+
+	selector := data[:4]
+	switch {
+	case bytes.Equal(selector, errorSelector):
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode Error(string): %w", err)
+		}
+		return reason, nil
+	case bytes.Equal(selector, panicSelector):
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode Panic(uint256): %w", err)
+		}
+		return "panic: " + reason, nil
+	default:
+		return "custom error " + hexutil.Encode(selector), nil
+	}
+}
+
+// RevertError is a call that reverted, with its decoded reason
+type RevertError struct {
+	// Reason is the decoded revert reason, see DecodeRevertReason, or empty
+	// if the revert carried no decodable data
+	Reason string
+	// Data is the raw revert data
+	Data []byte
+}
+
+func (e *RevertError) Error() string {
+	if e.Reason == "" {
+		return "execution reverted"
+	}
+	return "execution reverted: " + e.Reason
+}
+
+// asRevertError converts an RPC error reporting a revert into a
+// *RevertError, returning nil for any other error. Errors carrying revert
+// data, or whose message says the execution reverted, count as reverts;
+// a bare revert() or a require without a message has no data, and data
+// that cannot be decoded leaves Reason empty.
+func asRevertError(err error) *RevertError {
+	var data []byte
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+		data, _ = hexutil.Decode(fmt.Sprint(dataErr.ErrorData()))
+	} else if !strings.Contains(err.Error(), "execution reverted") {
+		return nil
+	}
+	reason, decodeErr := DecodeRevertReason(data)
+	if decodeErr != nil {
+		reason = ""
+	}
+	return &RevertError{Reason: reason, Data: data}
+}
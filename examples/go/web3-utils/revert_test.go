@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestDecodeRevertReason(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "error string",
+			data: "0x08c379a0" +
+				"0000000000000000000000000000000000000000000000000000000000000020" +
+				"000000000000000000000000000000000000000000000000000000000000001a" +
+				"4e6f7420656e6f7567682045746865722070726f76696465642e000000000000",
+			want: "Not enough Ether provided.",
+		},
+		{
+			name: "panic overflow",
+			data: "0x4e487b71" +
+				"0000000000000000000000000000000000000000000000000000000000000011",
+			want: "panic: arithmetic underflow or overflow",
+		},
+		{
+			name: "panic unknown code",
+			data: "0x4e487b71" +
+				"00000000000000000000000000000000000000000000000000000000000000ff",
+			want: "panic: unknown panic code: 0xff",
+		},
+		{
+			name: "custom error",
+			data: "0xe450d38c" +
+				"0000000000000000000000000000000000000000000000000000000000000001",
+			want: "custom error 0xe450d38c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeRevertReason(hexutil.MustDecode(tt.data))
+			if err != nil {
+				t.Fatalf("DecodeRevertReason: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("reason = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeRevertReasonInvalid(t *testing.T) {
+	if _, err := DecodeRevertReason([]byte{0x08, 0xc3}); !errors.Is(err, ErrNoRevertData) {
+		t.Fatalf("err = %v, want ErrNoRevertData", err)
+	}
+	if _, err := DecodeRevertReason(hexutil.MustDecode("0x08c379a0ff")); err == nil {
+		t.Fatal("expected error for truncated Error(string) payload")
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// SendETH transfers amount Wei from the key's account to to and returns the
+// transaction hash without waiting for it to be mined. It takes the nonce
+// from the NonceManager, so quick successive sends do not collide, or the
+// account's pending nonce if that is disabled with WithNonceManager. It uses
+// an estimated gas limit and sends an EIP-1559 transaction priced by
+// SuggestGasFees, or a legacy one on chains without a base fee.
+func (w *Web3Utils) SendETH(ctx context.Context, privateKey *ecdsa.PrivateKey, to common.Address, amount *big.Int) (common.Hash, error) {
+	from := PrivateKeyToAddress(privateKey)
+
+	chainID, err := w.ChainID(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	gas, err := w.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &to, Value: amount})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	maxFee, tip, err := w.SuggestGasFees(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	var hash common.Hash
+	send := func(nonce uint64) error {
+		var data types.TxData
+		if tip == nil {
+			data = &types.LegacyTx{Nonce: nonce, GasPrice: maxFee, Gas: gas, To: &to, Value: amount}
+		} else {
+			data = &types.DynamicFeeTx{ChainID: chainID, Nonce: nonce, GasTipCap: tip, GasFeeCap: maxFee, Gas: gas, To: &to, Value: amount}
+		}
+		tx, err := types.SignNewTx(privateKey, types.LatestSignerForChainID(chainID), data)
+		if err != nil {
+			return fmt.Errorf("failed to sign transaction: %w", err)
+		}
+		err = w.call(ctx, func(c *ethclient.Client) error {
+			return c.SendTransaction(ctx, tx)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to send transaction: %w", err)
+		}
+		hash = tx.Hash()
+		return nil
+	}
+
+	if w.nonces != nil {
+		err = w.nonces.Send(ctx, from, send)
+	} else {
+		var nonce uint64
+		err = w.call(ctx, func(c *ethclient.Client) (err error) {
+			nonce, err = c.PendingNonceAt(ctx, from)
+			return err
+		})
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to get nonce: %w", err)
+		}
+		err = send(nonce)
+	}
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return hash, nil
+}
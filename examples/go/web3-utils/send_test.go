@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// mockLedger makes the mock server behave like a tiny chain with id 1337: it
+// validates raw transactions and applies them to account balances and nonces
+type mockLedger struct {
+	mu       sync.Mutex
+	balances map[common.Address]*big.Int
+	nonces   map[common.Address]uint64
+	sent     []*types.Transaction
+}
+
+func newMockLedger(m *mockRPC, baseFee *big.Int) *mockLedger {
+	l := &mockLedger{balances: make(map[common.Address]*big.Int), nonces: make(map[common.Address]uint64)}
+	chainID := big.NewInt(1337)
+	decodeAddress := func(param json.RawMessage) common.Address {
+		var a common.Address
+		json.Unmarshal(param, &a)
+		return a
+	}
+
+	m.result("eth_chainId", (*hexutil.Big)(chainID))
+	m.result("eth_getBlockByNumber", mockBlock(&types.Header{Number: big.NewInt(1), BaseFee: baseFee}))
+	m.result("eth_maxPriorityFeePerGas", (*hexutil.Big)(gwei(1)))
+	m.result("eth_gasPrice", (*hexutil.Big)(gwei(20)))
+	m.result("eth_estimateGas", hexutil.Uint64(params.TxGas))
+	m.handle("eth_getTransactionCount", func(p []json.RawMessage) (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return hexutil.Uint64(l.nonces[decodeAddress(p[0])]), nil
+	})
+	m.handle("eth_getBalance", func(p []json.RawMessage) (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return (*hexutil.Big)(l.balance(decodeAddress(p[0]))), nil
+	})
+	m.handle("eth_sendRawTransaction", func(p []json.RawMessage) (interface{}, error) {
+		var raw hexutil.Bytes
+		json.Unmarshal(p[0], &raw)
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, err
+		}
+		from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+		if err != nil {
+			return nil, err
+		}
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if tx.Nonce() != l.nonces[from] {
+			return nil, fmt.Errorf("nonce too low")
+		}
+		fee := new(big.Int).Mul(EffectiveGasPrice(tx, baseFee), new(big.Int).SetUint64(tx.Gas()))
+		cost := new(big.Int).Add(fee, tx.Value())
+		if l.balance(from).Cmp(cost) < 0 {
+			return nil, fmt.Errorf("insufficient funds for gas * price + value")
+		}
+		l.balances[from] = new(big.Int).Sub(l.balance(from), cost)
+		l.balances[*tx.To()] = new(big.Int).Add(l.balance(*tx.To()), tx.Value())
+		l.nonces[from]++
+		l.sent = append(l.sent, tx)
+		return tx.Hash(), nil
+	})
+	return l
+}
+
+func (l *mockLedger) balance(a common.Address) *big.Int {
+	if b := l.balances[a]; b != nil {
+		return b
+	}
+	return new(big.Int)
+}
+
+func TestSendETH(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseFee *big.Int
+		txType  uint8
+	}{
+		{"eip1559", gwei(10), types.DynamicFeeTxType},
+		{"legacy", nil, types.LegacyTxType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockRPC(t)
+			ledger := newMockLedger(m, tt.baseFee)
+			senderKey, _ := crypto.GenerateKey()
+			recipientKey, _ := crypto.GenerateKey()
+			sender, recipient := PrivateKeyToAddress(senderKey), PrivateKeyToAddress(recipientKey)
+			ledger.balances[sender] = eth(10)
+
+			w := m.dial(t)
+			hash, err := w.SendETH(context.Background(), senderKey, recipient, eth(1))
+			if err != nil {
+				t.Fatalf("SendETH: %v", err)
+			}
+
+			if len(ledger.sent) != 1 || ledger.sent[0].Hash() != hash {
+				t.Fatalf("sent %d txs, want 1 with hash %s", len(ledger.sent), hash.Hex())
+			}
+			if tx := ledger.sent[0]; tx.Type() != tt.txType || tx.Nonce() != 0 || tx.Gas() != params.TxGas {
+				t.Fatalf("tx type %d nonce %d gas %d", tx.Type(), tx.Nonce(), tx.Gas())
+			}
+			balance, err := w.GetBalance(context.Background(), recipient.Hex())
+			if err != nil {
+				t.Fatalf("GetBalance: %v", err)
+			}
+			if balance.Cmp(eth(1)) != 0 {
+				t.Fatalf("recipient balance = %s, want 1 ETH", balance)
+			}
+
+			// A second transfer picks up the next nonce
+			if _, err := w.SendETH(context.Background(), senderKey, recipient, eth(1)); err != nil {
+				t.Fatalf("second SendETH: %v", err)
+			}
+		})
+	}
+}
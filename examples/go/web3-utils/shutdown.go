@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrShutdown is returned for calls made after Shutdown has begun
+var ErrShutdown = errors.New("web3utils is shut down")
+
+// lifecycle tracks in-flight RPC calls and running watchers so Shutdown can
+// wait for them to drain
+type lifecycle struct {
+	mu      sync.Mutex
+	closing bool
+	active  int
+	// stop is closed when shutdown begins, telling watchers to exit
+	stop chan struct{}
+	// drained is closed once shutdown has begun and nothing is active
+	drained chan struct{}
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{stop: make(chan struct{}), drained: make(chan struct{})}
+}
+
+// acquire registers an in-flight operation, failing once shutdown has begun
+func (l *lifecycle) acquire() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closing {
+		return ErrShutdown
+	}
+	l.active++
+	return nil
+}
+
+// release marks an operation registered with acquire as finished
+func (l *lifecycle) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	if l.closing && l.active == 0 {
+		close(l.drained)
+	}
+}
+
+// begin starts shutting down, reporting false if it had already started
+func (l *lifecycle) begin() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closing {
+		return false
+	}
+	l.closing = true
+	close(l.stop)
+	if l.active == 0 {
+		close(l.drained)
+	}
+	return true
+}
+
+// watch registers a long-running watcher. The returned context is cancelled
+// when ctx is or when shutdown begins; done must be called when the watcher
+// exits.
+func (w *Web3Utils) watch(ctx context.Context) (watchCtx context.Context, done func(), err error) {
+	if err := w.life.acquire(); err != nil {
+		return nil, nil, err
+	}
+	watchCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-w.life.stop:
+			cancel()
+		case <-watchCtx.Done():
+		}
+	}()
+	return watchCtx, func() {
+		cancel()
+		w.life.release()
+	}, nil
+}
+
+// Shutdown gracefully stops the instance: new calls fail with ErrShutdown,
+// running watchers are told to stop, and Shutdown waits for in-flight calls
+// and watchers to finish before closing the client. If ctx expires first the
+// client is closed anyway and ctx.Err() is returned.
+func (w *Web3Utils) Shutdown(ctx context.Context) error {
+	if !w.life.begin() {
+		return ErrShutdown
+	}
+	defer w.endpoints.close()
+
+	select {
+	case <-w.life.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestShutdownStopsWatchers(t *testing.T) {
+	m := newMockRPC(t)
+	advancingHead(m, 100)
+	w := m.dial(t, WithPollInterval(time.Millisecond))
+
+	stream := w.ConfirmationStream(context.Background(), testTxHash)
+	watcherDone := make(chan error, 1)
+	go func() {
+		watcherDone <- w.WatchBalances(context.Background(), []string{testAddress}, time.Millisecond, func(string, *big.Int, *big.Int) {})
+	}()
+	// Let the watchers start polling
+	for m.callCount("eth_blockNumber") < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	// Shutdown only returns once the watchers have exited
+	select {
+	case _, ok := <-stream:
+		if ok {
+			t.Fatal("confirmation stream still open after Shutdown")
+		}
+	default:
+		t.Fatal("confirmation stream not closed when Shutdown returned")
+	}
+	select {
+	case err := <-watcherDone:
+		if !errors.Is(err, ErrShutdown) {
+			t.Fatalf("WatchBalances returned %v, want ErrShutdown", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WatchBalances still running after Shutdown")
+	}
+
+	if _, err := w.GetBlockNumber(context.Background()); !errors.Is(err, ErrShutdown) {
+		t.Fatalf("call after Shutdown err = %v, want ErrShutdown", err)
+	}
+}
+
+func TestShutdownWaitsForInFlightCalls(t *testing.T) {
+	release := make(chan struct{})
+	m := newMockRPC(t)
+	m.handle("eth_blockNumber", func([]json.RawMessage) (interface{}, error) {
+		<-release
+		return "0x64", nil
+	})
+	w := m.dial(t)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := w.GetBlockNumber(context.Background())
+		result <- err
+	}()
+	for m.callCount("eth_blockNumber") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The call is still running, so a short deadline expires first
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := w.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown err = %v, want context.DeadlineExceeded", err)
+	}
+	close(release)
+	<-result
+}
+
+func TestShutdownDrainsInFlightCalls(t *testing.T) {
+	release := make(chan struct{})
+	m := newMockRPC(t)
+	m.handle("eth_blockNumber", func([]json.RawMessage) (interface{}, error) {
+		<-release
+		return "0x64", nil
+	})
+	w := m.dial(t)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := w.GetBlockNumber(context.Background())
+		result <- err
+	}()
+	for m.callCount("eth_blockNumber") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- w.Shutdown(context.Background()) }()
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned %v with a call in flight", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-shutdown; err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if err := <-result; err != nil {
+		t.Fatalf("in-flight call failed: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ChainBoundHash returns the digest signed by SignChainBoundMessage: the
+// EIP-191 personal message hash of the chain ID as a 32-byte big-endian
+// word followed by the message
+func ChainBoundHash(message []byte, chainID *big.Int) common.Hash {
+	payload := append(math.U256Bytes(new(big.Int).Set(chainID)), message...)
+	return HashMessage(payload, true)
+}
+
+// SignChainBoundMessage signs a message bound to a chain ID, so a signature
+// made for one chain cannot be replayed on another (e.g. across L2s)
+func SignChainBoundMessage(message []byte, chainID *big.Int, privateKey *ecdsa.PrivateKey) ([]byte, error) {
+	if chainID == nil || chainID.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid chain id %v", chainID)
+	}
+	return DeterministicSign(ChainBoundHash(message, chainID), privateKey)
+}
+
+// VerifyChainBoundMessage verifies a signature produced by
+// SignChainBoundMessage for the given chain ID. Signatures made for any
+// other chain are rejected. V may be 0/1 or 27/28.
+func VerifyChainBoundMessage(message []byte, signature []byte, chainID *big.Int, address common.Address) bool {
+	if chainID == nil || chainID.Sign() <= 0 {
+		return false
+	}
+	sig, err := normalizeSignature(signature)
+	if err != nil {
+		return false
+	}
+	pubKey, err := crypto.SigToPub(ChainBoundHash(message, chainID).Bytes(), sig)
+	if err != nil {
+		return false
+	}
+	return crypto.PubkeyToAddress(*pubKey) == address
+}
+
+// VerifyTxSigner reports whether tx was signed by expected. The sender is
+// recovered with the signer matching the transaction's type and chain ID, so
+// legacy, EIP-155 and typed transactions are all supported.
+func VerifyTxSigner(tx *types.Transaction, expected common.Address) (bool, error) {
+	sender, err := txSender(tx)
+	if err != nil {
+		return false, err
+	}
+	return sender == expected, nil
+}
+
+// txSender recovers the sender of tx with the signer matching its type and
+// chain ID
+func txSender(tx *types.Transaction) (common.Address, error) {
+	sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover sender: %w", err)
+	}
+	return sender, nil
+}
+
+// SignatureLayout is the order of the R, S and V components in a signature
+type SignatureLayout int
+
+const (
+	// LayoutRSV is [R || S || V], produced by go-ethereum and most wallets
+	LayoutRSV SignatureLayout = iota
+	// LayoutVRS is [V || R || S], produced by some signing libraries
+	LayoutVRS
+)
+
+// SignatureFormat describes how a signature is encoded
+type SignatureFormat struct {
+	Layout SignatureLayout
+	// Compact marks 64-byte EIP-2098 signatures, which fold V into the top
+	// bit of S. The layout is ignored for compact signatures.
+	Compact bool
+	// Hex marks signatures given as hex text, with or without a 0x prefix
+	Hex bool
+}
+
+// ParseSignature converts a signature in the given format to the 65-byte
+// [R || S || V] form with V as 0/1 expected by crypto.SigToPub
+func ParseSignature(signature []byte, format SignatureFormat) ([]byte, error) {
+	if format.Hex {
+		text := strings.TrimSpace(string(signature))
+		if !strings.HasPrefix(text, "0x") && !strings.HasPrefix(text, "0X") {
+			text = "0x" + text
+		}
+		decoded, err := hexutil.Decode(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex signature: %w", err)
+		}
+		signature = decoded
+	}
+
+	if format.Compact {
+		if len(signature) != 64 {
+			return nil, fmt.Errorf("invalid compact signature length: got %d, want 64", len(signature))
+		}
+		sig := make([]byte, crypto.SignatureLength)
+		copy(sig, signature)
+		sig[crypto.RecoveryIDOffset] = sig[32] >> 7
+		sig[32] &= 0x7f
+		return sig, nil
+	}
+
+	if format.Layout == LayoutVRS {
+		if len(signature) != crypto.SignatureLength {
+			return nil, fmt.Errorf("invalid signature length: got %d, want %d", len(signature), crypto.SignatureLength)
+		}
+		signature = append(append([]byte{}, signature[1:]...), signature[0])
+	}
+	return normalizeSignature(signature)
+}
+
+// VerifySignatureWithFormat is VerifySignature for signatures in any
+// supported format
+func VerifySignatureWithFormat(message []byte, signature []byte, format SignatureFormat, address common.Address) bool {
+	sig, err := ParseSignature(signature, format)
+	if err != nil {
+		return false
+	}
+	pubKey, err := crypto.SigToPub(HashMessage(message, false).Bytes(), sig)
+	if err != nil {
+		return false
+	}
+	return crypto.PubkeyToAddress(*pubKey) == address
+}
+
+// SignPersonalMessage signs message as personal_sign does, hashing it with
+// the EIP-191 "\x19Ethereum Signed Message:\n" + length prefix. The signature
+// is 65 bytes [R || S || V] with V as 27/28, matching wallets.
+func SignPersonalMessage(message []byte, privateKey *ecdsa.PrivateKey) ([]byte, error) {
+	sig, err := DeterministicSign(HashMessage(message, true), privateKey)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// VerifyPersonalSignature reports whether signature is a personal_sign
+// signature of message by address. V may be either 0/1 or 27/28.
+func VerifyPersonalSignature(message []byte, signature []byte, address common.Address) bool {
+	sig, err := normalizeSignature(signature)
+	if err != nil {
+		return false
+	}
+	pubKey, err := crypto.SigToPub(HashMessage(message, true).Bytes(), sig)
+	if err != nil {
+		return false
+	}
+	return crypto.PubkeyToAddress(*pubKey) == address
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestChainBoundMessage(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := PrivateKeyToAddress(key)
+	message := []byte("withdraw 1 ETH")
+
+	sig, err := SignChainBoundMessage(message, big.NewInt(1), key)
+	if err != nil {
+		t.Fatalf("SignChainBoundMessage: %v", err)
+	}
+	if !VerifyChainBoundMessage(message, sig, big.NewInt(1), addr) {
+		t.Fatal("signature rejected on its own chain")
+	}
+	if VerifyChainBoundMessage(message, sig, big.NewInt(10), addr) {
+		t.Fatal("chain 1 signature accepted for chain 10")
+	}
+
+	sig[64] += 27
+	if !VerifyChainBoundMessage(message, sig, big.NewInt(1), addr) {
+		t.Fatal("signature with V=27/28 rejected")
+	}
+	if _, err := SignChainBoundMessage(message, big.NewInt(0), key); err == nil {
+		t.Fatal("expected error for chain id 0")
+	}
+}
+
+func TestVerifyTxSigner(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := PrivateKeyToAddress(key)
+	to := common.HexToAddress(testAddress)
+
+	txs := map[string]struct {
+		signer types.Signer
+		data   types.TxData
+	}{
+		"homestead": {types.HomesteadSigner{}, &types.LegacyTx{GasPrice: gwei(10), Gas: 21000, To: &to}},
+		"eip155":    {types.NewEIP155Signer(big.NewInt(1)), &types.LegacyTx{GasPrice: gwei(10), Gas: 21000, To: &to}},
+		"dynamic": {types.LatestSignerForChainID(big.NewInt(10)), &types.DynamicFeeTx{
+			ChainID: big.NewInt(10), GasTipCap: gwei(1), GasFeeCap: gwei(10), Gas: 21000, To: &to,
+		}},
+	}
+	for name, tt := range txs {
+		tx, err := types.SignNewTx(key, tt.signer, tt.data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		ok, err := VerifyTxSigner(tx, signer)
+		if err != nil || !ok {
+			t.Errorf("%s: VerifyTxSigner(signer) = %v, %v; want true", name, ok, err)
+		}
+		ok, err = VerifyTxSigner(tx, to)
+		if err != nil || ok {
+			t.Errorf("%s: VerifyTxSigner(other) = %v, %v; want false", name, ok, err)
+		}
+	}
+}
+
+func TestVerifySignatureWithFormat(t *testing.T) {
+	key, err := GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := PrivateKeyToAddress(key)
+	message := []byte("Hello, Web3!")
+	rsv, err := SignMessage(message, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same signature as other libraries would encode it
+	wallet := append([]byte{}, rsv...)
+	wallet[64] += 27
+	vrs := append([]byte{wallet[64]}, wallet[:64]...)
+	compact := append([]byte{}, rsv[:64]...)
+	compact[32] |= rsv[64] << 7
+
+	tests := []struct {
+		name   string
+		sig    []byte
+		format SignatureFormat
+	}{
+		{"rsv", rsv, SignatureFormat{Layout: LayoutRSV}},
+		{"rsv 27/28", wallet, SignatureFormat{Layout: LayoutRSV}},
+		{"vrs", vrs, SignatureFormat{Layout: LayoutVRS}},
+		{"compact", compact, SignatureFormat{Compact: true}},
+		{"hex", []byte(hexutil.Encode(wallet)), SignatureFormat{Hex: true}},
+		{"vrs hex", []byte(hexutil.Encode(vrs)[2:]), SignatureFormat{Layout: LayoutVRS, Hex: true}},
+	}
+	for _, tt := range tests {
+		if !VerifySignatureWithFormat(message, tt.sig, tt.format, addr) {
+			t.Errorf("%s: valid signature rejected", tt.name)
+		}
+		if VerifySignatureWithFormat([]byte("other"), tt.sig, tt.format, addr) {
+			t.Errorf("%s: signature accepted for another message", tt.name)
+		}
+	}
+
+	if VerifySignatureWithFormat(message, vrs, SignatureFormat{Layout: LayoutRSV}, addr) {
+		t.Error("VRS signature accepted as RSV")
+	}
+}
+
+func TestPersonalSignMatchesEthers(t *testing.T) {
+	message := []byte("Hello World")
+	// ethers.utils.hashMessage("Hello World")
+	want := common.HexToHash("0xa1de988600a42c4b4ab089b619297c17d53cffae5d5120d82d8a92d0bb3b78f2")
+	if got := HashMessage(message, true); got != want {
+		t.Fatalf("personal message hash = %s, want %s", got.Hex(), want.Hex())
+	}
+
+	key, err := crypto.HexToECDSA("0123456789012345678901234567890123456789012345678901234567890123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := common.HexToAddress("0x14791697260E4c9A71f18484C9f997B308e59325")
+
+	// new ethers.Wallet(key).signMessage("Hello World")
+	ethersSig := hexutil.MustDecode("0xe0ed34fbbe927a58267ce2e8067a611c69869e20e731bc99187a8bc97058664c16de07f7660f06ce0985d1d8e063726783033fda59b307897f26a21392d62b3a1c")
+
+	sig, err := SignPersonalMessage(message, key)
+	if err != nil {
+		t.Fatalf("SignPersonalMessage: %v", err)
+	}
+	if !bytes.Equal(sig, ethersSig) {
+		t.Fatalf("signature = %x, want %x", sig, ethersSig)
+	}
+	if !VerifyPersonalSignature(message, ethersSig, address) {
+		t.Fatal("rejected the ethers signature")
+	}
+	raw := append([]byte{}, sig...)
+	raw[64] -= 27
+	if !VerifyPersonalSignature(message, raw, address) {
+		t.Fatal("rejected signature with V = 0/1")
+	}
+	if VerifyPersonalSignature([]byte("Hello World!"), sig, address) {
+		t.Fatal("accepted signature over a different message")
+	}
+}
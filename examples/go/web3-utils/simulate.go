@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// SimResult is the outcome of simulating a single transaction
+type SimResult struct {
+	Success      bool
+	GasUsed      uint64
+	ReturnData   []byte
+	RevertReason string
+}
+
+// StateOverride replaces account state (balance, nonce, code or storage)
+// for the duration of a single eth_call
+type StateOverride map[common.Address]gethclient.OverrideAccount
+
+// blockArg renders a block number as a JSON-RPC block parameter
+func blockArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(number)
+}
+
+// toCallArg renders msg as a JSON-RPC transaction call object
+func toCallArg(msg ethereum.CallMsg) map[string]interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	if msg.GasFeeCap != nil {
+		arg["maxFeePerGas"] = (*hexutil.Big)(msg.GasFeeCap)
+	}
+	if msg.GasTipCap != nil {
+		arg["maxPriorityFeePerGas"] = (*hexutil.Big)(msg.GasTipCap)
+	}
+	return arg
+}
+
+// txToCallMsg converts a signed transaction into the call it performs
+func txToCallMsg(tx *types.Transaction) (ethereum.CallMsg, error) {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return ethereum.CallMsg{}, fmt.Errorf("failed to recover sender: %w", err)
+	}
+	return ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}, nil
+}
+
+// simulatedCall is one call result of an eth_simulateV1 response
+type simulatedCall struct {
+	ReturnData hexutil.Bytes  `json:"returnData"`
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	Status     hexutil.Uint64 `json:"status"`
+	Error      *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// SimulateBundle executes txs in order on top of the state at blockNumber
+// (nil for latest) without broadcasting them, reporting each one's outcome.
+// Each transaction sees the effects of the ones before it.
+//
+// It uses eth_simulateV1 where the node supports it. Otherwise it falls back
+// to one eth_call per transaction, in which case transactions are simulated
+// independently and later ones do not observe earlier state changes.
+func (w *Web3Utils) SimulateBundle(ctx context.Context, txs []*types.Transaction, blockNumber *big.Int) ([]SimResult, error) {
+	msgs := make([]ethereum.CallMsg, len(txs))
+	calls := make([]map[string]interface{}, len(txs))
+	for i, tx := range txs {
+		msg, err := txToCallMsg(tx)
+		if err != nil {
+			return nil, fmt.Errorf("tx %d: %w", i, err)
+		}
+		msgs[i] = msg
+		calls[i] = toCallArg(msg)
+	}
+
+	var blocks []struct {
+		Calls []simulatedCall `json:"calls"`
+	}
+	payload := map[string]interface{}{
+		"blockStateCalls": []interface{}{map[string]interface{}{"calls": calls}},
+	}
+	err := w.call(ctx, func(c *ethclient.Client) error {
+		return c.Client().CallContext(ctx, &blocks, "eth_simulateV1", payload, blockArg(blockNumber))
+	})
+	if isMethodNotFound(err) {
+		return w.simulateSequential(ctx, msgs, blockNumber)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate bundle: %w", err)
+	}
+	if len(blocks) != 1 || len(blocks[0].Calls) != len(txs) {
+		return nil, fmt.Errorf("unexpected simulation result shape")
+	}
+
+	results := make([]SimResult, len(txs))
+	for i, call := range blocks[0].Calls {
+		results[i] = SimResult{
+			Success:    uint64(call.Status) == types.ReceiptStatusSuccessful,
+			GasUsed:    uint64(call.GasUsed),
+			ReturnData: call.ReturnData,
+		}
+		if !results[i].Success {
+			msg := "execution reverted"
+			if call.Error != nil {
+				msg = call.Error.Message
+			}
+			results[i].RevertReason = reasonOrMessage(call.ReturnData, msg)
+		}
+	}
+	return results, nil
+}
+
+// simulateSequential simulates each call on its own with eth_call
+func (w *Web3Utils) simulateSequential(ctx context.Context, msgs []ethereum.CallMsg, blockNumber *big.Int) ([]SimResult, error) {
+	results := make([]SimResult, len(msgs))
+	for i, msg := range msgs {
+		var out []byte
+		err := w.call(ctx, func(c *ethclient.Client) (err error) {
+			out, err = c.CallContract(ctx, msg, blockNumber)
+			return err
+		})
+		if err != nil {
+			var dataErr rpc.DataError
+			if !errors.As(err, &dataErr) {
+				return nil, fmt.Errorf("failed to simulate tx %d: %w", i, err)
+			}
+			data, _ := hexutil.Decode(fmt.Sprint(dataErr.ErrorData()))
+			results[i] = SimResult{ReturnData: data, RevertReason: reasonOrMessage(data, err.Error())}
+			continue
+		}
+
+		results[i] = SimResult{Success: true, ReturnData: out}
+		err = w.call(ctx, func(c *ethclient.Client) (err error) {
+			results[i].GasUsed, err = c.EstimateGas(ctx, msg)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas for tx %d: %w", i, err)
+		}
+	}
+	return results, nil
+}
+
+// reasonOrMessage decodes revert data, falling back to msg if there is none
+func reasonOrMessage(data []byte, msg string) string {
+	if reason, err := DecodeRevertReason(data); err == nil {
+		return reason
+	}
+	return msg
+}
+
+// isMethodNotFound reports whether err is the JSON-RPC "method not found" error
+func isMethodNotFound(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601
+}
+
+// CallWithOverrides executes msg against the state at blockNumber (nil for
+// latest) with the given accounts' state overridden, so calls can be run
+// against hypothetical balances, code or storage
+func (w *Web3Utils) CallWithOverrides(ctx context.Context, msg ethereum.CallMsg, overrides StateOverride, blockNumber *big.Int) ([]byte, error) {
+	ov := map[common.Address]gethclient.OverrideAccount(overrides)
+	var out []byte
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		out, err = gethclient.New(c.Client()).CallContract(ctx, msg, blockNumber, &ov)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call with overrides: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// bundleTxs signs an approve followed by a swap that depends on it
+func bundleTxs(t *testing.T) []*types.Transaction {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	var txs []*types.Transaction
+	for i, to := range []common.Address{token, testRouter} {
+		tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   big.NewInt(1),
+			Nonce:     uint64(i),
+			GasTipCap: gwei(1),
+			GasFeeCap: gwei(30),
+			Gas:       300000,
+			To:        &to,
+			Data:      []byte{byte(i + 1)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+func TestSimulateBundle(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_simulateV1", []interface{}{map[string]interface{}{
+		"calls": []interface{}{
+			map[string]interface{}{"returnData": "0x01", "gasUsed": "0xb411", "status": "0x1", "logs": []interface{}{}},
+			map[string]interface{}{
+				"returnData": "0x08c379a0" +
+					"0000000000000000000000000000000000000000000000000000000000000020" +
+					"0000000000000000000000000000000000000000000000000000000000000013" +
+					"494e53554646494349454e545f4f555450555400000000000000000000000000",
+				"gasUsed": "0x6d60",
+				"status":  "0x0",
+				"error":   map[string]interface{}{"code": 3, "message": "execution reverted"},
+			},
+		},
+	}})
+
+	txs := bundleTxs(t)
+	results, err := m.dial(t).SimulateBundle(context.Background(), txs, big.NewInt(1000))
+	if err != nil {
+		t.Fatalf("SimulateBundle: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if !results[0].Success || results[0].GasUsed != 46097 {
+		t.Fatalf("approve result = %+v, want success using 46097 gas", results[0])
+	}
+	if results[1].Success || results[1].GasUsed != 28000 || results[1].RevertReason != "INSUFFICIENT_OUTPUT" {
+		t.Fatalf("swap result = %+v, want revert INSUFFICIENT_OUTPUT using 28000 gas", results[1])
+	}
+
+	// Both calls must go out in order inside a single simulated block.
+	params := m.callParams("eth_simulateV1")[0]
+	var payload struct {
+		BlockStateCalls []struct {
+			Calls []struct {
+				To    common.Address `json:"to"`
+				Input string         `json:"input"`
+			} `json:"calls"`
+		} `json:"blockStateCalls"`
+	}
+	if err := json.Unmarshal(params[0], &payload); err != nil {
+		t.Fatal(err)
+	}
+	calls := payload.BlockStateCalls[0].Calls
+	if len(calls) != 2 || calls[0].To != *txs[0].To() || calls[1].To != testRouter || calls[1].Input != "0x02" {
+		t.Fatalf("unexpected simulate payload: %s", params[0])
+	}
+	if tag, _ := blockTag(params[1]); tag != 1000 {
+		t.Fatalf("block tag = %s, want 0x3e8", params[1])
+	}
+}
+
+func TestSimulateBundleSequentialFallback(t *testing.T) {
+	m := newMockRPC(t)
+	m.handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		var call struct {
+			To common.Address `json:"to"`
+		}
+		json.Unmarshal(params[0], &call)
+		if call.To == testRouter {
+			return nil, &rpcError{code: 3, msg: "execution reverted", data: "0x4e487b710000000000000000000000000000000000000000000000000000000000000011"}
+		}
+		return "0x01", nil
+	})
+	m.result("eth_estimateGas", "0xb411")
+
+	results, err := m.dial(t).SimulateBundle(context.Background(), bundleTxs(t), nil)
+	if err != nil {
+		t.Fatalf("SimulateBundle: %v", err)
+	}
+	if !results[0].Success || results[0].GasUsed != 46097 {
+		t.Fatalf("approve result = %+v", results[0])
+	}
+	if results[1].Success || results[1].RevertReason != "panic: arithmetic underflow or overflow" {
+		t.Fatalf("swap result = %+v", results[1])
+	}
+}
+
+func TestCallWithOverrides(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_call", "0x0000000000000000000000000000000000000000000000000000000000000001")
+
+	holder := common.HexToAddress(testAddress)
+	slot := common.HexToHash("0x05")
+	overrides := StateOverride{
+		holder: {Balance: EthToWei(big.NewFloat(100))},
+		testRouter: {
+			Code:      []byte{0x60, 0x00},
+			StateDiff: map[common.Hash]common.Hash{slot: common.HexToHash("0x01")},
+		},
+	}
+	out, err := m.dial(t).CallWithOverrides(context.Background(), ethereum.CallMsg{From: holder, To: &testRouter}, overrides, big.NewInt(1000))
+	if err != nil {
+		t.Fatalf("CallWithOverrides: %v", err)
+	}
+	if len(out) != 32 || out[31] != 1 {
+		t.Fatalf("unexpected call output %x", out)
+	}
+
+	params := m.callParams("eth_call")[0]
+	if len(params) != 3 {
+		t.Fatalf("eth_call got %d params, want 3", len(params))
+	}
+	var sent map[common.Address]struct {
+		Balance   string                      `json:"balance"`
+		Code      string                      `json:"code"`
+		StateDiff map[common.Hash]common.Hash `json:"stateDiff"`
+	}
+	if err := json.Unmarshal(params[2], &sent); err != nil {
+		t.Fatalf("decode overrides: %v", err)
+	}
+	if got := sent[holder].Balance; got != "0x56bc75e2d63100000" {
+		t.Fatalf("balance override = %s, want 100 ETH", got)
+	}
+	if got := sent[testRouter].Code; got != "0x6000" {
+		t.Fatalf("code override = %s, want 0x6000", got)
+	}
+	if got := sent[testRouter].StateDiff[slot]; got != common.HexToHash("0x01") {
+		t.Fatalf("storage override = %s", got.Hex())
+	}
+}
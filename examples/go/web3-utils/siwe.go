@@ -0,0 +1,262 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrInvalidSIWESignature is returned by VerifySIWE when the signature was
+// not made by the address in the message
+var ErrInvalidSIWESignature = errors.New("siwe signature does not match the message address")
+
+// siwePreamble follows the domain on the first line of a SIWE message
+const siwePreamble = " wants you to sign in with your Ethereum account:"
+
+// SIWEMessage holds the fields of a Sign-In with Ethereum (EIP-4361)
+// message. Zero ExpirationTime and NotBefore, and empty Statement and
+// RequestID, are left out of the message.
+type SIWEMessage struct {
+	// Domain is the RFC 3986 authority requesting the sign-in, optionally
+	// prefixed by a scheme
+	Domain    string
+	Address   common.Address
+	Statement string
+	URI       string
+	// Version is the message version, "1" if empty
+	Version string
+	ChainID uint64
+	// Nonce is a random string of at least 8 alphanumeric characters
+	// chosen by the server to prevent replay
+	Nonce string
+	// IssuedAt defaults to the current time when building a message
+	IssuedAt       time.Time
+	ExpirationTime time.Time
+	NotBefore      time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// BuildSIWEMessage renders m as an EIP-4361 message ready to be signed with
+// SignSIWE
+func BuildSIWEMessage(m SIWEMessage) (string, error) {
+	if m.Domain == "" || strings.ContainsAny(m.Domain, " \n") {
+		return "", fmt.Errorf("invalid siwe domain %q", m.Domain)
+	}
+	if strings.Contains(m.Statement, "\n") {
+		return "", fmt.Errorf("siwe statement must be a single line")
+	}
+	if m.URI == "" {
+		return "", fmt.Errorf("siwe uri is required")
+	}
+	if m.ChainID == 0 {
+		return "", fmt.Errorf("siwe chain id is required")
+	}
+	if !validSIWENonce(m.Nonce) {
+		return "", fmt.Errorf("siwe nonce must be at least 8 alphanumeric characters, got %q", m.Nonce)
+	}
+	if m.Version == "" {
+		m.Version = "1"
+	}
+	if m.IssuedAt.IsZero() {
+		m.IssuedAt = time.Now()
+	}
+
+	var b strings.Builder
+	b.WriteString(m.Domain + siwePreamble + "\n")
+	b.WriteString(m.Address.Hex() + "\n\n")
+	if m.Statement != "" {
+		b.WriteString(m.Statement + "\n")
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "URI: %s\n", m.URI)
+	fmt.Fprintf(&b, "Version: %s\n", m.Version)
+	fmt.Fprintf(&b, "Chain ID: %d\n", m.ChainID)
+	fmt.Fprintf(&b, "Nonce: %s\n", m.Nonce)
+	fmt.Fprintf(&b, "Issued At: %s", siweTime(m.IssuedAt))
+	if !m.ExpirationTime.IsZero() {
+		fmt.Fprintf(&b, "\nExpiration Time: %s", siweTime(m.ExpirationTime))
+	}
+	if !m.NotBefore.IsZero() {
+		fmt.Fprintf(&b, "\nNot Before: %s", siweTime(m.NotBefore))
+	}
+	if m.RequestID != "" {
+		fmt.Fprintf(&b, "\nRequest ID: %s", m.RequestID)
+	}
+	if len(m.Resources) > 0 {
+		b.WriteString("\nResources:")
+		for _, r := range m.Resources {
+			b.WriteString("\n- " + r)
+		}
+	}
+	return b.String(), nil
+}
+
+// siweTime formats t as an RFC 3339 timestamp in UTC
+func siweTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// validSIWENonce reports whether nonce is at least 8 alphanumeric characters
+func validSIWENonce(nonce string) bool {
+	if len(nonce) < 8 {
+		return false
+	}
+	for _, r := range nonce {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSIWEMessage parses an EIP-4361 message. As the spec requires, the
+// address must be in its EIP-55 checksummed form.
+func ParseSIWEMessage(message string) (*SIWEMessage, error) {
+	lines := strings.Split(message, "\n")
+	if len(lines) < 4 {
+		return nil, fmt.Errorf("siwe message is too short")
+	}
+
+	var m SIWEMessage
+	domain, ok := strings.CutSuffix(lines[0], siwePreamble)
+	if !ok || domain == "" {
+		return nil, fmt.Errorf("siwe message has an invalid first line %q", lines[0])
+	}
+	m.Domain = domain
+	address, err := ValidateAddress(lines[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid siwe address: %w", err)
+	}
+	if lines[1] != address.Hex() {
+		return nil, fmt.Errorf("siwe address %q is not EIP-55 checksummed, want %s", lines[1], address.Hex())
+	}
+	m.Address = address
+	if lines[2] != "" {
+		return nil, fmt.Errorf("siwe message is missing the blank line after the address")
+	}
+	rest := lines[3:]
+	if rest[0] != "" {
+		m.Statement = rest[0]
+		rest = rest[1:]
+		if len(rest) == 0 || rest[0] != "" {
+			return nil, fmt.Errorf("siwe message is missing the blank line after the statement")
+		}
+	}
+	rest = rest[1:]
+
+	// field consumes the next line if it starts with name
+	field := func(name string, required bool) (string, error) {
+		if len(rest) > 0 {
+			if value, ok := strings.CutPrefix(rest[0], name+": "); ok {
+				rest = rest[1:]
+				return value, nil
+			}
+		}
+		if required {
+			return "", fmt.Errorf("siwe message is missing %s", name)
+		}
+		return "", nil
+	}
+	timeField := func(name string, required bool) (time.Time, error) {
+		value, err := field(name, required)
+		if err != nil || value == "" {
+			return time.Time{}, err
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid siwe %s: %w", name, err)
+		}
+		return t, nil
+	}
+
+	if m.URI, err = field("URI", true); err != nil {
+		return nil, err
+	}
+	if m.Version, err = field("Version", true); err != nil {
+		return nil, err
+	}
+	if m.Version != "1" {
+		return nil, fmt.Errorf("unsupported siwe version %q", m.Version)
+	}
+	chainID, err := field("Chain ID", true)
+	if err != nil {
+		return nil, err
+	}
+	if m.ChainID, err = strconv.ParseUint(chainID, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid siwe chain id %q", chainID)
+	}
+	if m.Nonce, err = field("Nonce", true); err != nil {
+		return nil, err
+	}
+	if !validSIWENonce(m.Nonce) {
+		return nil, fmt.Errorf("invalid siwe nonce %q", m.Nonce)
+	}
+	if m.IssuedAt, err = timeField("Issued At", true); err != nil {
+		return nil, err
+	}
+	if m.ExpirationTime, err = timeField("Expiration Time", false); err != nil {
+		return nil, err
+	}
+	if m.NotBefore, err = timeField("Not Before", false); err != nil {
+		return nil, err
+	}
+	if m.RequestID, err = field("Request ID", false); err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 && rest[0] == "Resources:" {
+		rest = rest[1:]
+		for len(rest) > 0 {
+			resource, ok := strings.CutPrefix(rest[0], "- ")
+			if !ok {
+				break
+			}
+			m.Resources = append(m.Resources, resource)
+			rest = rest[1:]
+		}
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("unexpected siwe line %q", rest[0])
+	}
+	return &m, nil
+}
+
+// SignSIWE signs a message built by BuildSIWEMessage with personal_sign, as
+// a wallet would
+func SignSIWE(message string, privateKey *ecdsa.PrivateKey) ([]byte, error) {
+	return SignPersonalMessage([]byte(message), privateKey)
+}
+
+// VerifySIWE parses a signed EIP-4361 message and checks that it was issued
+// for domain with the server's nonce, is within its validity window and is
+// signed by the address it names. It returns the parsed message, or
+// ErrMessageExpired once the expiration time has passed and
+// ErrInvalidSIWESignature for a signature by another account.
+func VerifySIWE(message string, signature []byte, domain, nonce string) (*SIWEMessage, error) {
+	m, err := ParseSIWEMessage(message)
+	if err != nil {
+		return nil, err
+	}
+	if m.Domain != domain {
+		return nil, fmt.Errorf("siwe domain %q does not match %q", m.Domain, domain)
+	}
+	if m.Nonce != nonce {
+		return nil, fmt.Errorf("siwe nonce %q does not match", m.Nonce)
+	}
+	now := time.Now()
+	if !m.ExpirationTime.IsZero() && !now.Before(m.ExpirationTime) {
+		return nil, ErrMessageExpired
+	}
+	if !m.NotBefore.IsZero() && now.Before(m.NotBefore) {
+		return nil, fmt.Errorf("siwe message is not valid before %s", siweTime(m.NotBefore))
+	}
+	if !VerifyPersonalSignature([]byte(message), signature, m.Address) {
+		return nil, ErrInvalidSIWESignature
+	}
+	return m, nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestBuildSIWEMessage(t *testing.T) {
+	// The example message from EIP-4361
+	const want = "service.invalid wants you to sign in with your Ethereum account:\n" +
+		"0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2\n" +
+		"\n" +
+		"I accept the ServiceOrg Terms of Service: https://service.invalid/tos\n" +
+		"\n" +
+		"URI: https://service.invalid/login\n" +
+		"Version: 1\n" +
+		"Chain ID: 1\n" +
+		"Nonce: 32891756\n" +
+		"Issued At: 2021-09-30T16:25:24Z\n" +
+		"Resources:\n" +
+		"- ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/\n" +
+		"- https://example.com/my-web2-claim.json"
+
+	msg, err := BuildSIWEMessage(SIWEMessage{
+		Domain:    "service.invalid",
+		Address:   common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"),
+		Statement: "I accept the ServiceOrg Terms of Service: https://service.invalid/tos",
+		URI:       "https://service.invalid/login",
+		ChainID:   1,
+		Nonce:     "32891756",
+		IssuedAt:  time.Date(2021, 9, 30, 16, 25, 24, 0, time.UTC),
+		Resources: []string{
+			"ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/",
+			"https://example.com/my-web2-claim.json",
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildSIWEMessage: %v", err)
+	}
+	if msg != want {
+		t.Fatalf("message =\n%s\nwant\n%s", msg, want)
+	}
+	parsed, err := ParseSIWEMessage(want)
+	if err != nil {
+		t.Fatalf("ParseSIWEMessage: %v", err)
+	}
+	if again, _ := BuildSIWEMessage(*parsed); again != want {
+		t.Fatalf("rebuilt message =\n%s\nwant\n%s", again, want)
+	}
+
+	if _, err := BuildSIWEMessage(SIWEMessage{Domain: "a.invalid", URI: "https://a.invalid", ChainID: 1, Nonce: "short"}); err == nil {
+		t.Fatal("accepted a nonce shorter than 8 characters")
+	}
+}
+
+func TestVerifySIWE(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := PrivateKeyToAddress(key)
+	issued := time.Now().Add(-time.Minute).Truncate(time.Second)
+	expiry := issued.Add(time.Hour)
+
+	msg, err := BuildSIWEMessage(SIWEMessage{
+		Domain:         "app.example.com",
+		Address:        signer,
+		URI:            "https://app.example.com/login",
+		ChainID:        11155111,
+		Nonce:          "k3v9Qx7Lm2",
+		IssuedAt:       issued,
+		ExpirationTime: expiry,
+		RequestID:      "req-42",
+	})
+	if err != nil {
+		t.Fatalf("BuildSIWEMessage: %v", err)
+	}
+	sig, err := SignSIWE(msg, key)
+	if err != nil {
+		t.Fatalf("SignSIWE: %v", err)
+	}
+
+	m, err := VerifySIWE(msg, sig, "app.example.com", "k3v9Qx7Lm2")
+	if err != nil {
+		t.Fatalf("VerifySIWE: %v", err)
+	}
+	if m.Address != signer || m.ChainID != 11155111 || m.URI != "https://app.example.com/login" ||
+		m.Version != "1" || m.Statement != "" || m.RequestID != "req-42" ||
+		!m.IssuedAt.Equal(issued) || !m.ExpirationTime.Equal(expiry) {
+		t.Fatalf("parsed message = %+v", m)
+	}
+
+	if _, err := VerifySIWE(msg, sig, "evil.example.com", "k3v9Qx7Lm2"); err == nil {
+		t.Fatal("accepted a message for another domain")
+	}
+	if _, err := VerifySIWE(msg, sig, "app.example.com", "otherNonce1"); err == nil {
+		t.Fatal("accepted a message with another nonce")
+	}
+	other, _ := crypto.GenerateKey()
+	forged, _ := SignSIWE(msg, other)
+	if _, err := VerifySIWE(msg, forged, "app.example.com", "k3v9Qx7Lm2"); !errors.Is(err, ErrInvalidSIWESignature) {
+		t.Fatalf("err = %v, want ErrInvalidSIWESignature", err)
+	}
+
+	expired, _ := BuildSIWEMessage(SIWEMessage{
+		Domain: "app.example.com", Address: signer, URI: "https://app.example.com/login", ChainID: 1,
+		Nonce: "k3v9Qx7Lm2", IssuedAt: issued, ExpirationTime: issued.Add(time.Second),
+	})
+	sig, _ = SignSIWE(expired, key)
+	if _, err := VerifySIWE(expired, sig, "app.example.com", "k3v9Qx7Lm2"); !errors.Is(err, ErrMessageExpired) {
+		t.Fatalf("err = %v, want ErrMessageExpired", err)
+	}
+}
+
+func TestParseSIWEMessageRequiresChecksum(t *testing.T) {
+	signer := common.HexToAddress("0x14791697260E4c9A71f18484C9f997B308e59325")
+	msg, err := BuildSIWEMessage(SIWEMessage{
+		Domain: "app.example.com", Address: signer, URI: "https://app.example.com/login", ChainID: 1,
+		Nonce: "k3v9Qx7Lm2", IssuedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("BuildSIWEMessage: %v", err)
+	}
+	if _, err := ParseSIWEMessage(msg); err != nil {
+		t.Fatalf("ParseSIWEMessage: %v", err)
+	}
+
+	lower := strings.Replace(msg, signer.Hex(), strings.ToLower(signer.Hex()), 1)
+	if _, err := ParseSIWEMessage(lower); err == nil {
+		t.Fatal("accepted an all-lowercase address")
+	}
+}
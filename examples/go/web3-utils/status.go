@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ErrTxDropped is returned by WaitForReceipt when the transaction has
+// neither been mined nor is still known to the node, usually because it was
+// dropped from the mempool or replaced by another transaction with its nonce
+var ErrTxDropped = errors.New("transaction dropped or replaced")
+
+// Status is the lifecycle state of a transaction
+type Status int
+
+const (
+	StatusNotFound Status = iota
+	StatusPending
+	StatusSuccess
+	StatusReverted
+)
+
+// String returns the human-readable name of the status
+func (s Status) String() string {
+	switch s {
+	case StatusPending:
+		return "pending"
+	case StatusSuccess:
+		return "success"
+	case StatusReverted:
+		return "reverted"
+	default:
+		return "not found"
+	}
+}
+
+// TransactionStatus classifies a single transaction
+func (w *Web3Utils) TransactionStatus(ctx context.Context, txHash string) (Status, error) {
+	hash := common.HexToHash(txHash)
+
+	var receipt *types.Receipt
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		receipt, err = c.TransactionReceipt(ctx, hash)
+		return err
+	})
+	switch {
+	case err == nil:
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			return StatusSuccess, nil
+		}
+		return StatusReverted, nil
+	case !errors.Is(err, ethereum.NotFound):
+		return StatusNotFound, fmt.Errorf("failed to get receipt of %s: %w", txHash, err)
+	}
+
+	// No receipt yet: the transaction is either pending or unknown.
+	err = w.call(ctx, func(c *ethclient.Client) error {
+		_, _, err := c.TransactionByHash(ctx, hash)
+		return err
+	})
+	switch {
+	case err == nil:
+		return StatusPending, nil
+	case errors.Is(err, ethereum.NotFound):
+		return StatusNotFound, nil
+	default:
+		return StatusNotFound, fmt.Errorf("failed to get transaction %s: %w", txHash, err)
+	}
+}
+
+// TransactionStatuses classifies many transactions concurrently. The result
+// is keyed by the hashes exactly as given.
+func (w *Web3Utils) TransactionStatuses(ctx context.Context, txHashes []string) (map[string]Status, error) {
+	statuses := make([]Status, len(txHashes))
+	errs := make([]error, len(txHashes))
+
+	var wg sync.WaitGroup
+	for i, txHash := range txHashes {
+		wg.Add(1)
+		go func(i int, txHash string) {
+			defer wg.Done()
+			statuses[i], errs[i] = w.TransactionStatus(ctx, txHash)
+		}(i, txHash)
+	}
+	wg.Wait()
+
+	result := make(map[string]Status, len(txHashes))
+	for i, txHash := range txHashes {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		result[txHash] = statuses[i]
+	}
+	return result, nil
+}
+
+// WaitForReceipt polls every pollInterval until txHash is mined and returns
+// its receipt. A missing receipt means keep waiting as long as the node still
+// knows the transaction; once it does not, ErrTxDropped is returned. Any other
+// lookup error is returned immediately, as is ctx.Err() when ctx ends first.
+func (w *Web3Utils) WaitForReceipt(ctx context.Context, txHash common.Hash, pollInterval time.Duration) (*types.Receipt, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		var receipt *types.Receipt
+		err := w.call(ctx, func(c *ethclient.Client) (err error) {
+			receipt, err = c.TransactionReceipt(ctx, txHash)
+			return err
+		})
+		if err == nil {
+			return receipt, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("failed to get receipt of %s: %w", txHash.Hex(), err)
+		}
+
+		err = w.call(ctx, func(c *ethclient.Client) error {
+			_, _, err := c.TransactionByHash(ctx, txHash)
+			return err
+		})
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, ErrTxDropped
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transaction %s: %w", txHash.Hex(), err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
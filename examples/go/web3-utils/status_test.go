@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestTransactionStatuses(t *testing.T) {
+	pendingTx := legacyTx(t, 0, common.HexToAddress(testAddress), big.NewInt(1), gwei(10))
+	var (
+		success  = common.Hash{0x01}.Hex()
+		reverted = common.Hash{0x02}.Hex()
+		pending  = pendingTx.Hash().Hex()
+		missing  = common.Hash{0x04}.Hex()
+	)
+
+	m := newMockRPC(t)
+	m.handle("eth_getTransactionReceipt", func(params []json.RawMessage) (interface{}, error) {
+		var hash common.Hash
+		if err := json.Unmarshal(params[0], &hash); err != nil {
+			return nil, err
+		}
+		switch hash.Hex() {
+		case success:
+			return mockReceipt(success, 100, types.ReceiptStatusSuccessful), nil
+		case reverted:
+			return mockReceipt(reverted, 101, types.ReceiptStatusFailed), nil
+		}
+		return nil, nil
+	})
+	m.handle("eth_getTransactionByHash", func(params []json.RawMessage) (interface{}, error) {
+		var hash common.Hash
+		if err := json.Unmarshal(params[0], &hash); err != nil {
+			return nil, err
+		}
+		if hash.Hex() == pending {
+			return rpcTxJSON(t, pendingTx, nil), nil
+		}
+		return nil, nil
+	})
+
+	hashes := []string{success, reverted, pending, missing}
+	statuses, err := m.dial(t).TransactionStatuses(context.Background(), hashes)
+	if err != nil {
+		t.Fatalf("TransactionStatuses: %v", err)
+	}
+
+	want := map[string]Status{
+		success:  StatusSuccess,
+		reverted: StatusReverted,
+		pending:  StatusPending,
+		missing:  StatusNotFound,
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("got %d statuses, want %d", len(statuses), len(want))
+	}
+	for hash, status := range want {
+		if statuses[hash] != status {
+			t.Errorf("status of %s = %v, want %v", hash, statuses[hash], status)
+		}
+	}
+}
+
+func TestWaitForReceipt(t *testing.T) {
+	tx := legacyTx(t, 0, common.HexToAddress(testAddress), big.NewInt(1), gwei(10))
+	m := newMockRPC(t)
+	m.result("eth_getTransactionByHash", tx)
+	// The receipt appears on the third poll
+	m.handle("eth_getTransactionReceipt", func([]json.RawMessage) (interface{}, error) {
+		if m.callCount("eth_getTransactionReceipt") < 3 {
+			return nil, nil
+		}
+		return mockReceipt(tx.Hash().Hex(), 100, types.ReceiptStatusSuccessful), nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	receipt, err := m.dial(t).WaitForReceipt(ctx, tx.Hash(), time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForReceipt: %v", err)
+	}
+	if receipt.TxHash != tx.Hash() || receipt.BlockNumber.Int64() != 100 {
+		t.Fatalf("receipt = %+v", receipt)
+	}
+	if n := m.callCount("eth_getTransactionReceipt"); n != 3 {
+		t.Fatalf("polled %d times, want 3", n)
+	}
+}
+
+func TestWaitForReceiptTimeout(t *testing.T) {
+	tx := legacyTx(t, 0, common.HexToAddress(testAddress), big.NewInt(1), gwei(10))
+	m := newMockRPC(t)
+	m.result("eth_getTransactionByHash", tx)
+	m.result("eth_getTransactionReceipt", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := m.dial(t).WaitForReceipt(ctx, tx.Hash(), time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestWaitForReceiptDropped(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_getTransactionByHash", nil)
+	m.result("eth_getTransactionReceipt", nil)
+
+	_, err := m.dial(t).WaitForReceipt(context.Background(), common.HexToHash(testTxHash), time.Millisecond)
+	if !errors.Is(err, ErrTxDropped) {
+		t.Fatalf("err = %v, want ErrTxDropped", err)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrNotWebsocket is returned by SubscribeNewHeads when the active endpoint
+// is not a websocket connection
+var ErrNotWebsocket = errors.New("subscriptions require a websocket (ws:// or wss://) endpoint")
+
+// SubscribeNewHeads subscribes to new block headers over the active
+// endpoint, which must be a ws:// or wss:// URL. Headers are forwarded on
+// the returned channel, which is closed when ctx is cancelled, Shutdown is
+// called or the subscription fails. After the channel closes, Err reports
+// why: nil for a clean stop, or the subscription error.
+func (w *Web3Utils) SubscribeNewHeads(ctx context.Context) (<-chan *types.Header, error) {
+	i := w.endpoints.current()
+	if !w.endpoints.websocket[i] {
+		return nil, ErrNotWebsocket
+	}
+	c, err := w.endpoints.client(i)
+	if err != nil {
+		return nil, err
+	}
+	ctx, done, err := w.watch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	heads := make(chan *types.Header)
+	sub, err := c.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		done()
+		return nil, fmt.Errorf("failed to subscribe to new heads: %w", err)
+	}
+	w.setHeadsErr(nil)
+
+	out := make(chan *types.Header)
+	go func() {
+		defer close(out)
+		defer done()
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case header := <-heads:
+				select {
+				case out <- header:
+				case <-ctx.Done():
+					return
+				}
+			case err := <-sub.Err():
+				w.setHeadsErr(err)
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// Err returns the error that ended the latest SubscribeNewHeads
+// subscription, or nil if it is still running or was stopped cleanly
+func (w *Web3Utils) Err() error {
+	w.headsMu.Lock()
+	defer w.headsMu.Unlock()
+	return w.headsErr
+}
+
+func (w *Web3Utils) setHeadsErr(err error) {
+	w.headsMu.Lock()
+	defer w.headsMu.Unlock()
+	w.headsErr = err
+}
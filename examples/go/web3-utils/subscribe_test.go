@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// headService serves eth_subscribe("newHeads"), emitting its headers to
+// every subscriber
+type headService struct {
+	headers []*types.Header
+}
+
+func (s *headService) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		for _, header := range s.headers {
+			if err := notifier.Notify(sub.ID, header); err != nil {
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// wsNode starts a websocket JSON-RPC server emitting n headers per
+// subscription and returns it with its ws:// URL
+func wsNode(t *testing.T, n int) (*rpc.Server, string) {
+	t.Helper()
+	svc := &headService{}
+	for i := 1; i <= n; i++ {
+		svc.headers = append(svc.headers, &types.Header{Number: big.NewInt(int64(i)), Difficulty: new(big.Int)})
+	}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", svc); err != nil {
+		t.Fatal(err)
+	}
+	httpSrv := httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+	t.Cleanup(func() {
+		srv.Stop()
+		httpSrv.Close()
+	})
+	return srv, "ws" + strings.TrimPrefix(httpSrv.URL, "http")
+}
+
+func TestSubscribeNewHeads(t *testing.T) {
+	srv, url := wsNode(t, 3)
+	w, err := NewWeb3Utils(url)
+	if err != nil {
+		t.Fatalf("NewWeb3Utils: %v", err)
+	}
+	defer w.Close()
+
+	heads, err := w.SubscribeNewHeads(context.Background())
+	if err != nil {
+		t.Fatalf("SubscribeNewHeads: %v", err)
+	}
+	for want := int64(1); want <= 3; want++ {
+		select {
+		case header := <-heads:
+			if header.Number.Int64() != want {
+				t.Fatalf("header %d, want %d", header.Number, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for header %d", want)
+		}
+	}
+
+	// The node going away ends the subscription with an error
+	srv.Stop()
+	select {
+	case _, ok := <-heads:
+		if ok {
+			t.Fatal("unexpected header after the node stopped")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel not closed after the node stopped")
+	}
+	if w.Err() == nil {
+		t.Fatal("Err() = nil after the subscription failed")
+	}
+}
+
+func TestSubscribeNewHeadsCancel(t *testing.T) {
+	_, url := wsNode(t, 0)
+	w, err := NewWeb3Utils(url)
+	if err != nil {
+		t.Fatalf("NewWeb3Utils: %v", err)
+	}
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	heads, err := w.SubscribeNewHeads(ctx)
+	if err != nil {
+		t.Fatalf("SubscribeNewHeads: %v", err)
+	}
+	cancel()
+	select {
+	case _, ok := <-heads:
+		if ok {
+			t.Fatal("unexpected header")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+	if err := w.Err(); err != nil {
+		t.Fatalf("Err() = %v after a clean stop, want nil", err)
+	}
+}
+
+func TestSubscribeNewHeadsRequiresWebsocket(t *testing.T) {
+	_, err := newMockRPC(t).dial(t).SubscribeNewHeads(context.Background())
+	if !errors.Is(err, ErrNotWebsocket) {
+		t.Fatalf("err = %v, want ErrNotWebsocket", err)
+	}
+}
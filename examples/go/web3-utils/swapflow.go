@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+)
+
+var (
+	// approveSelector is the selector of ERC-20 approve(address,uint256)
+	approveSelector = []byte{0x09, 0x5e, 0xa7, 0xb3}
+	// allowanceSelector is the selector of ERC-20 allowance(address,address)
+	allowanceSelector = []byte{0xdd, 0x62, 0xed, 0x3e}
+)
+
+// allowanceSlotSearchDepth is how many storage slots allowanceSlot tries as
+// the position of a token's allowance mapping
+const allowanceSlotSearchDepth = 10
+
+// EstimateSwapFlowGas estimates the total gas of approving router's spender
+// for an unlimited amount of token and then sending swapData to router, both
+// from from. The swap cannot be estimated against current state because it
+// would fail for lack of allowance, so it is estimated with the approval's
+// effect applied as a state override of the token's allowance storage slot.
+// The slot is located by probing the usual Solidity mapping positions, so
+// tokens with unusual storage layouts are reported as unsupported.
+func (w *Web3Utils) EstimateSwapFlowGas(ctx context.Context, from, token, spender, router string, swapData []byte) (uint64, error) {
+	owner := common.HexToAddress(from)
+	tokenAddr := common.HexToAddress(token)
+	spenderAddr := common.HexToAddress(spender)
+	routerAddr := common.HexToAddress(router)
+
+	approveData := append(append(append([]byte{}, approveSelector...),
+		common.LeftPadBytes(spenderAddr.Bytes(), 32)...), math.U256Bytes(new(big.Int).Set(math.MaxBig256))...)
+	approveGas, err := w.EstimateGas(ctx, ethereum.CallMsg{From: owner, To: &tokenAddr, Data: approveData})
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate approve: %w", err)
+	}
+
+	slot, err := w.allowanceSlot(ctx, tokenAddr, owner, spenderAddr)
+	if err != nil {
+		return 0, err
+	}
+	overrides := StateOverride{tokenAddr: {StateDiff: map[common.Hash]common.Hash{slot: maxUint256Hash()}}}
+	msg := ethereum.CallMsg{From: owner, To: &routerAddr, Data: swapData}
+	var swapGas hexutil.Uint64
+	err = w.call(ctx, func(c *ethclient.Client) error {
+		ov := map[common.Address]gethclient.OverrideAccount(overrides)
+		return c.Client().CallContext(ctx, &swapGas, "eth_estimateGas", toCallArg(msg), "latest", ov)
+	})
+	if err != nil {
+		if revert := asRevertError(err); revert != nil {
+			err = revert
+		}
+		return 0, fmt.Errorf("failed to estimate swap: %w", err)
+	}
+	return approveGas + uint64(swapGas), nil
+}
+
+// allowanceSlot finds the storage slot holding token's allowance of owner
+// for spender by overriding the candidate slot of each likely mapping
+// position and checking whether allowance(owner, spender) reflects it
+func (w *Web3Utils) allowanceSlot(ctx context.Context, token, owner, spender common.Address) (common.Hash, error) {
+	data := append(append(append([]byte{}, allowanceSelector...),
+		common.LeftPadBytes(owner.Bytes(), 32)...), common.LeftPadBytes(spender.Bytes(), 32)...)
+	want := maxUint256Hash()
+	for position := int64(0); position < allowanceSlotSearchDepth; position++ {
+		slot := allowanceSlotAt(owner, spender, position)
+		overrides := StateOverride{token: {StateDiff: map[common.Hash]common.Hash{slot: want}}}
+		out, err := w.CallWithOverrides(ctx, ethereum.CallMsg{To: &token, Data: data}, overrides, nil)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to read allowance: %w", err)
+		}
+		if common.BytesToHash(out) == want {
+			return slot, nil
+		}
+	}
+	return common.Hash{}, fmt.Errorf("token %s has an unsupported storage layout: allowance slot not found", token.Hex())
+}
+
+// allowanceSlotAt returns the storage slot of allowance[owner][spender] for
+// a Solidity mapping(address => mapping(address => uint256)) declared at
+// position
+func allowanceSlotAt(owner, spender common.Address, position int64) common.Hash {
+	inner := crypto.Keccak256(common.LeftPadBytes(owner.Bytes(), 32), common.LeftPadBytes(big.NewInt(position).Bytes(), 32))
+	return crypto.Keccak256Hash(common.LeftPadBytes(spender.Bytes(), 32), inner)
+}
+
+// maxUint256Hash returns 2^256-1 as a storage word
+func maxUint256Hash() common.Hash {
+	return common.BytesToHash(math.U256Bytes(new(big.Int).Set(math.MaxBig256)))
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestEstimateSwapFlowGas(t *testing.T) {
+	owner := common.HexToAddress(testAddress)
+	token := common.HexToAddress(testTokenAddress)
+	// OpenZeppelin's ERC20 declares _allowances second, at slot 1
+	allowance := allowanceSlotAt(owner, testRouter, 1)
+	swapData := []byte{0x38, 0xed, 0x17, 0x39}
+
+	// overridden reports whether a call's state override grants the allowance
+	overridden := func(params []json.RawMessage) bool {
+		if len(params) < 3 {
+			return false
+		}
+		var ov map[common.Address]struct {
+			StateDiff map[common.Hash]common.Hash `json:"stateDiff"`
+		}
+		if err := json.Unmarshal(params[2], &ov); err != nil {
+			return false
+		}
+		return ov[token].StateDiff[allowance] == maxUint256Hash()
+	}
+	decodeInput := func(params []json.RawMessage) []byte {
+		var call struct {
+			Input hexutil.Bytes `json:"input"`
+		}
+		json.Unmarshal(params[0], &call)
+		return call.Input
+	}
+
+	m := newMockRPC(t)
+	m.handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		if !bytes.HasPrefix(decodeInput(params), allowanceSelector) || !overridden(params) {
+			return hexutil.Bytes(make([]byte, 32)), nil
+		}
+		return maxUint256Hash(), nil
+	})
+	m.handle("eth_estimateGas", func(params []json.RawMessage) (interface{}, error) {
+		input := decodeInput(params)
+		switch {
+		case bytes.HasPrefix(input, approveSelector):
+			return hexutil.Uint64(46_000), nil
+		case bytes.Equal(input, swapData) && overridden(params):
+			return hexutil.Uint64(150_000), nil
+		}
+		return nil, &rpcError{code: 3, msg: "execution reverted", data: "0x08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"000000000000000000000000000000000000000000000000000000000000001d" +
+			"45524332303a20696e73756666696369656e7420616c6c6f77616e6365000000"}
+	})
+
+	gas, err := m.dial(t).EstimateSwapFlowGas(context.Background(), owner.Hex(), token.Hex(), testRouter.Hex(), testRouter.Hex(), swapData)
+	if err != nil {
+		t.Fatalf("EstimateSwapFlowGas: %v", err)
+	}
+	if gas != 196_000 {
+		t.Fatalf("gas = %d, want 196000", gas)
+	}
+	// Slots 0 and 1 were probed
+	if n := m.callCount("eth_call"); n != 2 {
+		t.Fatalf("eth_call called %d times, want 2", n)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrMessageExpired is returned by VerifyTimedMessage for messages whose
+// embedded expiry has passed
+var ErrMessageExpired = errors.New("signed message has expired")
+
+// timedMessageSeparator precedes the expiry appended by TimedMessage
+const timedMessageSeparator = "\nExpires: "
+
+// TimedMessage encodes payload with an expiry for signing with
+// SignPersonalMessage. The expiry is appended as a human-readable line,
+// "\nExpires: " followed by the time in RFC 3339 at second precision and
+// UTC, so wallets show it to the user before they sign.
+func TimedMessage(payload []byte, expiry time.Time) []byte {
+	suffix := timedMessageSeparator + expiry.UTC().Format(time.RFC3339)
+	return append(append([]byte{}, payload...), suffix...)
+}
+
+// parseTimedMessage splits a message encoded by TimedMessage into its
+// payload and expiry
+func parseTimedMessage(message []byte) ([]byte, time.Time, error) {
+	i := bytes.LastIndex(message, []byte(timedMessageSeparator))
+	if i < 0 {
+		return nil, time.Time{}, fmt.Errorf("message has no expiry")
+	}
+	expiry, err := time.Parse(time.RFC3339, string(message[i+len(timedMessageSeparator):]))
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("invalid message expiry: %w", err)
+	}
+	return message[:i], expiry, nil
+}
+
+// VerifyTimedMessage verifies a personal_sign signature of a message encoded
+// by TimedMessage. The message must embed expiry, so the signer committed to
+// it, and is rejected with ErrMessageExpired once expiry has passed even if
+// the signature is valid. It returns false without an error when the
+// signature is not by expected.
+func VerifyTimedMessage(message []byte, signature []byte, expiry time.Time, expected common.Address) (bool, error) {
+	_, embedded, err := parseTimedMessage(message)
+	if err != nil {
+		return false, err
+	}
+	if !embedded.Equal(expiry.Truncate(time.Second)) {
+		return false, fmt.Errorf("message expiry %s does not match %s", embedded.Format(time.RFC3339), expiry.UTC().Format(time.RFC3339))
+	}
+	if !time.Now().Before(embedded) {
+		return false, ErrMessageExpired
+	}
+	return VerifyPersonalSignature(message, signature, expected), nil
+}
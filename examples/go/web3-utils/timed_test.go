@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestVerifyTimedMessage(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := PrivateKeyToAddress(key)
+
+	sign := func(expiry time.Time) ([]byte, []byte) {
+		msg := TimedMessage([]byte("Sign in to example.com"), expiry)
+		sig, err := SignPersonalMessage(msg, key)
+		if err != nil {
+			t.Fatalf("SignPersonalMessage: %v", err)
+		}
+		return msg, sig
+	}
+
+	fresh := time.Now().Add(time.Hour)
+	msg, sig := sign(fresh)
+	if ok, err := VerifyTimedMessage(msg, sig, fresh, signer); err != nil || !ok {
+		t.Fatalf("fresh message: ok=%v err=%v", ok, err)
+	}
+	if ok, err := VerifyTimedMessage(msg, sig, fresh, common.Address{}); err != nil || ok {
+		t.Fatalf("wrong signer: ok=%v err=%v", ok, err)
+	}
+	if _, err := VerifyTimedMessage(msg, sig, fresh.Add(time.Hour), signer); err == nil {
+		t.Fatal("accepted a different expiry than the one signed")
+	}
+
+	expired := time.Now().Add(-time.Minute)
+	msg, sig = sign(expired)
+	ok, err := VerifyTimedMessage(msg, sig, expired, signer)
+	if !errors.Is(err, ErrMessageExpired) || ok {
+		t.Fatalf("expired message: ok=%v err=%v, want ErrMessageExpired", ok, err)
+	}
+
+	if _, err := VerifyTimedMessage([]byte("no expiry"), sig, fresh, signer); err == nil {
+		t.Fatal("accepted a message without an embedded expiry")
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Contract standards reported by DetectContractStandard
+const (
+	StandardERC20   = "ERC-20"
+	StandardERC721  = "ERC-721"
+	StandardERC1155 = "ERC-1155"
+	StandardUnknown = "unknown"
+)
+
+var (
+	// supportsInterfaceSelector is the selector of ERC-165 supportsInterface(bytes4)
+	supportsInterfaceSelector = []byte{0x01, 0xff, 0xc9, 0xa7}
+	// decimalsSelector is the selector of ERC-20 decimals()
+	decimalsSelector = []byte{0x31, 0x3c, 0xe5, 0x67}
+	// symbolSelector is the selector of ERC-20 symbol()
+	symbolSelector = []byte{0x95, 0xd8, 0x9b, 0x41}
+	// nameSelector is the selector of ERC-20 name()
+	nameSelector = []byte{0x06, 0xfd, 0xde, 0x03}
+	// balanceOfSelector is the selector of ERC-20 balanceOf(address)
+	balanceOfSelector = []byte{0x70, 0xa0, 0x82, 0x31}
+
+	erc721InterfaceID  = [4]byte{0x80, 0xac, 0x58, 0xcd}
+	erc1155InterfaceID = [4]byte{0xd9, 0xb6, 0x7a, 0x26}
+)
+
+// callContract performs a read-only eth_call at the latest block
+func (w *Web3Utils) callContract(ctx context.Context, to common.Address, data []byte) ([]byte, error) {
+	var out []byte
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		out, err = c.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+		return err
+	})
+	return out, err
+}
+
+// supportsInterface asks a contract whether it implements an ERC-165
+// interface. Contracts that revert or return garbage are treated as not
+// supporting it.
+func (w *Web3Utils) supportsInterface(ctx context.Context, contract common.Address, id [4]byte) bool {
+	data := make([]byte, 36)
+	copy(data, supportsInterfaceSelector)
+	copy(data[4:], id[:])
+	out, err := w.callContract(ctx, contract, data)
+	return err == nil && len(out) == 32 && new(big.Int).SetBytes(out).Cmp(big.NewInt(1)) == 0
+}
+
+// DetectContractStandard probes the contract at address and returns
+// StandardERC1155 or StandardERC721 if it advertises the interface via
+// ERC-165, StandardERC20 if it answers decimals() and symbol(), and
+// StandardUnknown otherwise, including for addresses without code
+func (w *Web3Utils) DetectContractStandard(ctx context.Context, address string) (string, error) {
+	contract := common.HexToAddress(address)
+
+	var code []byte
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		code, err = c.CodeAt(ctx, contract, nil)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get code: %w", err)
+	}
+	if len(code) == 0 {
+		return StandardUnknown, nil
+	}
+
+	if w.supportsInterface(ctx, contract, erc1155InterfaceID) {
+		return StandardERC1155, nil
+	}
+	if w.supportsInterface(ctx, contract, erc721InterfaceID) {
+		return StandardERC721, nil
+	}
+
+	decimals, err := w.callContract(ctx, contract, decimalsSelector)
+	if err != nil || len(decimals) != 32 || new(big.Int).SetBytes(decimals).Cmp(big.NewInt(255)) > 0 {
+		return StandardUnknown, nil
+	}
+	if symbol, err := w.callContract(ctx, contract, symbolSelector); err != nil || len(symbol) == 0 {
+		return StandardUnknown, nil
+	}
+	return StandardERC20, nil
+}
+
+// TokenBalance returns the raw ERC-20 balance of holder, in the token's
+// smallest unit (see ScaleTokenAmount)
+func (w *Web3Utils) TokenBalance(ctx context.Context, tokenAddress, holderAddress common.Address) (*big.Int, error) {
+	data := make([]byte, 36)
+	copy(data, balanceOfSelector)
+	copy(data[4+12:], holderAddress.Bytes())
+	out, err := w.callContract(ctx, tokenAddress, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token balance: %w", err)
+	}
+	if len(out) != 32 {
+		return nil, fmt.Errorf("invalid balanceOf result length %d", len(out))
+	}
+	return new(big.Int).SetBytes(out), nil
+}
+
+// TokenMetadata returns the name, symbol and decimals of an ERC-20 token.
+// Names and symbols may be ABI strings or, for older tokens such as MKR,
+// null-padded bytes32 values.
+func (w *Web3Utils) TokenMetadata(ctx context.Context, tokenAddress common.Address) (name string, symbol string, decimals uint8, err error) {
+	if name, err = w.tokenString(ctx, tokenAddress, nameSelector); err != nil {
+		return "", "", 0, fmt.Errorf("failed to get token name: %w", err)
+	}
+	if symbol, err = w.tokenString(ctx, tokenAddress, symbolSelector); err != nil {
+		return "", "", 0, fmt.Errorf("failed to get token symbol: %w", err)
+	}
+
+	out, err := w.callContract(ctx, tokenAddress, decimalsSelector)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to get token decimals: %w", err)
+	}
+	if len(out) != 32 || new(big.Int).SetBytes(out).Cmp(big.NewInt(255)) > 0 {
+		return "", "", 0, fmt.Errorf("invalid decimals result %x", out)
+	}
+	return name, symbol, out[31], nil
+}
+
+// tokenString calls a string getter such as name() or symbol()
+func (w *Web3Utils) tokenString(ctx context.Context, token common.Address, selector []byte) (string, error) {
+	out, err := w.callContract(ctx, token, selector)
+	if err != nil {
+		return "", err
+	}
+	return decodeTokenString(out)
+}
+
+// decodeTokenString decodes a string getter's return data, which is either an
+// ABI-encoded string or a single null-padded bytes32 word
+func decodeTokenString(out []byte) (string, error) {
+	if len(out) == 32 {
+		return string(bytes.TrimRight(out, "\x00")), nil
+	}
+	if len(out) < 64 {
+		return "", fmt.Errorf("invalid string result length %d", len(out))
+	}
+	offset := new(big.Int).SetBytes(out[:32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(out)-32) {
+		return "", fmt.Errorf("invalid string offset %s", offset)
+	}
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(out[start-32 : start])
+	if !length.IsUint64() || length.Uint64() > uint64(len(out))-start {
+		return "", fmt.Errorf("invalid string length %s", length)
+	}
+	return string(out[start : start+length.Uint64()]), nil
+}
+
+// ScaleTokenAmount converts a raw token amount into whole tokens using the
+// token's decimals, e.g. 1500000 with 6 decimals is 1.5
+func ScaleTokenAmount(amount *big.Int, decimals uint8) *big.Float {
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return newFloat().Quo(newFloat().SetInt(amount), newFloat().SetInt(unit))
+}
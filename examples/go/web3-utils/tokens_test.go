@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// testTokenAddress is the USDC contract on mainnet
+const testTokenAddress = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+
+const (
+	abiTrue  = "0x0000000000000000000000000000000000000000000000000000000000000001"
+	abiFalse = "0x0000000000000000000000000000000000000000000000000000000000000000"
+)
+
+// mockContract answers eth_call by the hex prefix of the call data; calls
+// without a matching prefix revert
+func mockContract(m *mockRPC, responses map[string]string) {
+	m.result("eth_getCode", "0x6080604052")
+	m.handle("eth_call", func(params []json.RawMessage) (interface{}, error) {
+		var call struct {
+			Input hexutil.Bytes `json:"input"`
+			Data  hexutil.Bytes `json:"data"`
+		}
+		if err := json.Unmarshal(params[0], &call); err != nil {
+			return nil, err
+		}
+		input := call.Input
+		if len(input) == 0 {
+			input = call.Data
+		}
+		data := hexutil.Encode(input)
+		for prefix, out := range responses {
+			if strings.HasPrefix(data, prefix) {
+				return out, nil
+			}
+		}
+		return nil, &rpcError{code: 3, msg: "execution reverted"}
+	})
+}
+
+func TestDetectContractStandard(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]string
+		want      string
+	}{
+		{
+			name: "erc1155",
+			responses: map[string]string{
+				"0x01ffc9a7d9b67a26": abiTrue,
+				"0x01ffc9a780ac58cd": abiFalse,
+			},
+			want: StandardERC1155,
+		},
+		{
+			name: "erc721",
+			responses: map[string]string{
+				"0x01ffc9a7d9b67a26": abiFalse,
+				"0x01ffc9a780ac58cd": abiTrue,
+			},
+			want: StandardERC721,
+		},
+		{
+			name: "erc20",
+			responses: map[string]string{
+				"0x313ce567": "0x0000000000000000000000000000000000000000000000000000000000000012",
+				"0x95d89b41": "0x0000000000000000000000000000000000000000000000000000000000000020" +
+					"0000000000000000000000000000000000000000000000000000000000000004" +
+					"5553444300000000000000000000000000000000000000000000000000000000",
+			},
+			want: StandardERC20,
+		},
+		{
+			name:      "unknown",
+			responses: map[string]string{"0x01ffc9a7": abiFalse},
+			want:      StandardUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockRPC(t)
+			mockContract(m, tt.responses)
+			got, err := m.dial(t).DetectContractStandard(context.Background(), testAddress)
+			if err != nil {
+				t.Fatalf("DetectContractStandard: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("standard = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectContractStandardNoCode(t *testing.T) {
+	m := newMockRPC(t)
+	m.result("eth_getCode", "0x")
+	got, err := m.dial(t).DetectContractStandard(context.Background(), testAddress)
+	if err != nil {
+		t.Fatalf("DetectContractStandard: %v", err)
+	}
+	if got != StandardUnknown {
+		t.Fatalf("standard = %s, want unknown", got)
+	}
+	if n := m.callCount("eth_call"); n != 0 {
+		t.Fatalf("eth_call made %d times for an EOA", n)
+	}
+}
+
+// abiString ABI-encodes s as the return value of a string getter
+func abiString(s string) string {
+	out := make([]byte, 64+(len(s)+31)/32*32)
+	out[31] = 0x20
+	out[63] = byte(len(s))
+	copy(out[64:], s)
+	return hexutil.Encode(out)
+}
+
+// abiBytes32 encodes s as a null-padded bytes32 return value
+func abiBytes32(s string) string {
+	out := make([]byte, 32)
+	copy(out, s)
+	return hexutil.Encode(out)
+}
+
+func TestTokenBalance(t *testing.T) {
+	m := newMockRPC(t)
+	mockContract(m, map[string]string{
+		"0x70a08231000000000000000000000000" + strings.ToLower(testAddress[2:]): "0x" + strings.Repeat("0", 58) + "16e360",
+	})
+
+	balance, err := m.dial(t).TokenBalance(context.Background(), common.HexToAddress(testTokenAddress), common.HexToAddress(testAddress))
+	if err != nil {
+		t.Fatalf("TokenBalance: %v", err)
+	}
+	if balance.Int64() != 1500000 {
+		t.Fatalf("balance = %s, want 1500000", balance)
+	}
+	if got := ScaleTokenAmount(balance, 6).Text('f', 2); got != "1.50" {
+		t.Fatalf("scaled balance = %s, want 1.50", got)
+	}
+}
+
+func TestTokenMetadata(t *testing.T) {
+	tests := []struct {
+		name         string
+		nameOut      string
+		symbolOut    string
+		decimalsOut  string
+		wantName     string
+		wantSymbol   string
+		wantDecimals uint8
+	}{
+		{"string", abiString("USD Coin"), abiString("USDC"), "0x" + strings.Repeat("0", 63) + "6", "USD Coin", "USDC", 6},
+		{"bytes32", abiBytes32("Maker"), abiBytes32("MKR"), "0x" + strings.Repeat("0", 62) + "12", "Maker", "MKR", 18},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockRPC(t)
+			mockContract(m, map[string]string{
+				"0x06fdde03": tt.nameOut,
+				"0x95d89b41": tt.symbolOut,
+				"0x313ce567": tt.decimalsOut,
+			})
+
+			name, symbol, decimals, err := m.dial(t).TokenMetadata(context.Background(), common.HexToAddress(testTokenAddress))
+			if err != nil {
+				t.Fatalf("TokenMetadata: %v", err)
+			}
+			if name != tt.wantName || symbol != tt.wantSymbol || decimals != tt.wantDecimals {
+				t.Fatalf("metadata = %q %q %d, want %q %q %d", name, symbol, decimals, tt.wantName, tt.wantSymbol, tt.wantDecimals)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ErrTracingUnavailable is returned by TraceTxGas when the endpoint does not
+// serve the debug namespace
+var ErrTracingUnavailable = errors.New("debug_traceTransaction not supported by this endpoint")
+
+// callFrame is a node of the callTracer output
+type callFrame struct {
+	Type    string          `json:"type"`
+	To      *common.Address `json:"to"`
+	GasUsed hexutil.Uint64  `json:"gasUsed"`
+	Calls   []callFrame     `json:"calls"`
+}
+
+// TraceTxGas attributes the gas used by a transaction to its internal calls
+// using debug_traceTransaction with the callTracer. Each call is keyed by its
+// position in the call tree, its type and its target, e.g. "0 CALL 0xabc..."
+// for the top-level call and "0.1 STATICCALL 0xdef..." for its second
+// subcall. Values are the gas used by the call including its subcalls.
+// Tracing usually needs an archive or self-hosted node; other endpoints
+// yield ErrTracingUnavailable.
+func (w *Web3Utils) TraceTxGas(ctx context.Context, txHash string) (map[string]uint64, error) {
+	var root callFrame
+	err := w.call(ctx, func(c *ethclient.Client) error {
+		return c.Client().CallContext(ctx, &root, "debug_traceTransaction", common.HexToHash(txHash), map[string]string{"tracer": "callTracer"})
+	})
+	if isMethodNotFound(err) {
+		return nil, ErrTracingUnavailable
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace transaction: %w", err)
+	}
+
+	gas := make(map[string]uint64)
+	addCallGas(gas, "0", root)
+	return gas, nil
+}
+
+// addCallGas records frame and its subcalls under path
+func addCallGas(gas map[string]uint64, path string, frame callFrame) {
+	key := path + " " + frame.Type
+	if frame.To != nil {
+		key += " " + frame.To.Hex()
+	}
+	gas[key] = uint64(frame.GasUsed)
+	for i, sub := range frame.Calls {
+		addCallGas(gas, path+"."+strconv.Itoa(i), sub)
+	}
+}
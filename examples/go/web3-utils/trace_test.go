@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestTraceTxGas(t *testing.T) {
+	// A router call that reads a price and performs a token transfer
+	const trace = `{
+		"type": "CALL",
+		"from": "0xd8da6bf26964af9d7eed9e03e53415d37aa96045",
+		"to": "0x7a250d5630b4cf539739df2c5dacb4c659f2488d",
+		"gas": "0x30d40", "gasUsed": "0x1d4c0", "input": "0x38ed1739",
+		"calls": [
+			{"type": "STATICCALL", "from": "0x7a250d5630b4cf539739df2c5dacb4c659f2488d",
+			 "to": "0xb4e16d0168e52d35cacd2c6185b44281ec28c9dc", "gas": "0x1000", "gasUsed": "0x9c4", "input": "0x0902f1ac"},
+			{"type": "CALL", "from": "0x7a250d5630b4cf539739df2c5dacb4c659f2488d",
+			 "to": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", "gas": "0x10000", "gasUsed": "0x7530", "input": "0xa9059cbb",
+			 "calls": [
+				{"type": "DELEGATECALL", "from": "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48",
+				 "to": "0x43506849d7c04f9138d1a2050bbf3a0c054402dd", "gas": "0xf000", "gasUsed": "0x61a8", "input": "0xa9059cbb"}
+			 ]}
+		]
+	}`
+	m := newMockRPC(t)
+	m.handle("debug_traceTransaction", func(params []json.RawMessage) (interface{}, error) {
+		var config struct {
+			Tracer string `json:"tracer"`
+		}
+		if err := json.Unmarshal(params[1], &config); err != nil || config.Tracer != "callTracer" {
+			t.Errorf("tracer config = %s, want callTracer", params[1])
+		}
+		return json.RawMessage(trace), nil
+	})
+
+	gas, err := m.dial(t).TraceTxGas(context.Background(), testTxHash)
+	if err != nil {
+		t.Fatalf("TraceTxGas: %v", err)
+	}
+	want := map[string]uint64{
+		"0 CALL 0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D":             120000,
+		"0.0 STATICCALL 0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc":     2500,
+		"0.1 CALL 0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48":           30000,
+		"0.1.0 DELEGATECALL 0x43506849D7C04F9138D1A2050bbF3A0c054402dd": 25000,
+	}
+	if len(gas) != len(want) {
+		t.Fatalf("gas = %v, want %v", gas, want)
+	}
+	for call, used := range want {
+		if gas[call] != used {
+			t.Errorf("gas[%q] = %d, want %d", call, gas[call], used)
+		}
+	}
+}
+
+func TestTraceTxGasUnavailable(t *testing.T) {
+	_, err := newMockRPC(t).dial(t).TraceTxGas(context.Background(), testTxHash)
+	if !errors.Is(err, ErrTracingUnavailable) {
+		t.Fatalf("err = %v, want ErrTracingUnavailable", err)
+	}
+}
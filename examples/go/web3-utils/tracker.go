@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// ErrTrackerStarted is returned when starting a GasTracker a second time
+var ErrTrackerStarted = errors.New("gas tracker already started")
+
+// GasTracker polls the gas price at a fixed interval and keeps the most
+// recent samples in a ring buffer
+type GasTracker struct {
+	utils    *Web3Utils
+	interval time.Duration
+	history  *GasPriceHistory
+
+	mu      sync.Mutex
+	started bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewGasTracker creates a tracker polling every interval and keeping the
+// last capacity samples
+func NewGasTracker(utils *Web3Utils, interval time.Duration, capacity int) *GasTracker {
+	return &GasTracker{
+		utils:    utils,
+		interval: interval,
+		history:  NewGasPriceHistory(capacity),
+	}
+}
+
+// Start begins polling in a background goroutine until ctx is cancelled,
+// Stop is called or the Web3Utils is shut down. A tracker can only be
+// started once.
+func (t *GasTracker) Start(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.started {
+		return ErrTrackerStarted
+	}
+	ctx, done, err := t.utils.watch(ctx)
+	if err != nil {
+		return err
+	}
+	t.started = true
+
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		defer done()
+
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			// Failed polls are skipped; the next tick tries again
+			if price, err := t.utils.GetGasPrice(ctx); err == nil {
+				t.history.Record(price, time.Now())
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop halts polling and waits for the polling goroutine to exit. It is safe
+// to call more than once, and before Start.
+func (t *GasTracker) Stop() {
+	t.mu.Lock()
+	cancel := t.cancel
+	t.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	t.wg.Wait()
+}
+
+// History returns the collected samples, oldest first
+func (t *GasTracker) History() []GasSample {
+	return t.history.Samples()
+}
+
+// Stats returns the minimum, maximum and average gas price over the
+// collected samples
+func (t *GasTracker) Stats() (min, max, avg *big.Int, err error) {
+	samples := t.History()
+	if len(samples) == 0 {
+		return nil, nil, nil, ErrNoGasHistory
+	}
+
+	sum := new(big.Int)
+	for _, s := range samples {
+		if min == nil || s.Price.Cmp(min) < 0 {
+			min = s.Price
+		}
+		if max == nil || s.Price.Cmp(max) > 0 {
+			max = s.Price
+		}
+		sum.Add(sum, s.Price)
+	}
+	avg = sum.Div(sum, big.NewInt(int64(len(samples))))
+	return new(big.Int).Set(min), new(big.Int).Set(max), avg, nil
+}
+
+// WatchBelow polls the gas price every tracker interval and calls callback
+// each time the price crosses from at or above threshold to below it. It is
+// edge-triggered: the callback fires once per crossing, not on every poll
+// that stays below, and the first poll only records which side of the
+// threshold the price starts on. Failed polls are skipped. It blocks until
+// ctx is cancelled and then returns ctx.Err(), or ErrShutdown if stopped by
+// Shutdown. It does not need the tracker to be started.
+func (t *GasTracker) WatchBelow(ctx context.Context, threshold *big.Int, callback func(price *big.Int)) error {
+	return t.watchCrossing(ctx, &thresholdCrossing{threshold: threshold, below: true}, callback)
+}
+
+// WatchAbove is the counterpart of WatchBelow, calling callback each time
+// the price crosses from at or below threshold to above it
+func (t *GasTracker) WatchAbove(ctx context.Context, threshold *big.Int, callback func(price *big.Int)) error {
+	return t.watchCrossing(ctx, &thresholdCrossing{threshold: threshold}, callback)
+}
+
+// watchCrossing polls the gas price and calls callback whenever crossing
+// reports an edge
+func (t *GasTracker) watchCrossing(ctx context.Context, crossing *thresholdCrossing, callback func(price *big.Int)) error {
+	parent := ctx
+	ctx, done, err := t.utils.watch(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		if price, err := t.utils.GetGasPrice(ctx); err == nil && crossing.observe(price) {
+			callback(price)
+		}
+
+		select {
+		case <-ctx.Done():
+			if parent.Err() == nil {
+				return ErrShutdown
+			}
+			return parent.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// thresholdCrossing detects a price series crossing a threshold in one
+// direction by remembering which side the previous sample was on
+type thresholdCrossing struct {
+	threshold *big.Int
+	// below selects crossings to below the threshold rather than above it
+	below bool
+
+	seen   bool
+	inside bool
+}
+
+// observe records price and reports whether it just crossed into the
+// watched side of the threshold
+func (c *thresholdCrossing) observe(price *big.Int) bool {
+	cmp := price.Cmp(c.threshold)
+	inside := cmp > 0
+	if c.below {
+		inside = cmp < 0
+	}
+	crossed := c.seen && inside && !c.inside
+	c.seen, c.inside = true, inside
+	return crossed
+}
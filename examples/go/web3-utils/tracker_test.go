@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestGasTracker(t *testing.T) {
+	// Prices cycle through 10, 20, 30, 40, 50 gwei
+	var polls atomic.Int64
+	m := newMockRPC(t)
+	m.handle("eth_gasPrice", func([]json.RawMessage) (interface{}, error) {
+		n := polls.Add(1)
+		return hexutil.Big(*gwei(10 * ((n-1)%5 + 1))), nil
+	})
+
+	tracker := NewGasTracker(m.dial(t), time.Millisecond, 4)
+	if _, _, _, err := tracker.Stats(); !errors.Is(err, ErrNoGasHistory) {
+		t.Fatalf("Stats before start err = %v, want ErrNoGasHistory", err)
+	}
+	if err := tracker.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := tracker.Start(context.Background()); !errors.Is(err, ErrTrackerStarted) {
+		t.Fatalf("second Start err = %v, want ErrTrackerStarted", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for polls.Load() < 5 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	tracker.Stop()
+	tracker.Stop()
+
+	// Nothing is recorded after Stop returns
+	history := tracker.History()
+	time.Sleep(10 * time.Millisecond)
+	if after := tracker.History(); len(after) != len(history) || after[len(after)-1].Time != history[len(history)-1].Time {
+		t.Fatal("tracker kept recording after Stop")
+	}
+	if len(history) != 4 {
+		t.Fatalf("history has %d samples, want ring buffer capacity 4", len(history))
+	}
+	min, max, avg, err := tracker.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	sum := new(big.Int)
+	for i, s := range history {
+		sum.Add(sum, s.Price)
+		if i > 0 && s.Time.Before(history[i-1].Time) {
+			t.Fatal("history not in chronological order")
+		}
+		if s.Price.Cmp(min) < 0 || s.Price.Cmp(max) > 0 {
+			t.Fatalf("sample %v outside [%v, %v]", s.Price, min, max)
+		}
+	}
+	if want := sum.Div(sum, big.NewInt(4)); avg.Cmp(want) != 0 {
+		t.Fatalf("avg = %v, want %v", avg, want)
+	}
+}
+
+func TestGasTrackerWatchCrossings(t *testing.T) {
+	// Prices in gwei around a 20 gwei threshold; 0 is a failed poll
+	script := []int64{30, 25, 15, 10, 0, 18, 20, 19, 19, 30, 21}
+	tests := map[string]struct {
+		script []int64
+		above  bool
+		want   []int64
+	}{
+		"below":                     {script, false, []int64{15, 19}},
+		"above":                     {script, true, []int64{30}},
+		"starts below":              {[]int64{10, 15, 25, 5}, false, []int64{5}},
+		"at threshold is not above": {[]int64{20, 25, 20, 21}, true, []int64{25, 21}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var polls atomic.Int64
+			m := newMockRPC(t)
+			m.handle("eth_gasPrice", func([]json.RawMessage) (interface{}, error) {
+				n := polls.Add(1)
+				if n > int64(len(tt.script)) {
+					n = int64(len(tt.script))
+				}
+				if tt.script[n-1] == 0 {
+					return nil, &rpcError{code: -32000, msg: "unavailable"}
+				}
+				return hexutil.Big(*gwei(tt.script[n-1])), nil
+			})
+			tracker := NewGasTracker(m.dial(t), time.Millisecond, 4)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var got []int64
+			callback := func(price *big.Int) {
+				got = append(got, new(big.Int).Div(price, gwei(1)).Int64())
+			}
+			errc := make(chan error, 1)
+			go func() {
+				if tt.above {
+					errc <- tracker.WatchAbove(ctx, gwei(20), callback)
+				} else {
+					errc <- tracker.WatchBelow(ctx, gwei(20), callback)
+				}
+			}()
+
+			// One poll past the script means every scripted price was seen
+			deadline := time.Now().Add(5 * time.Second)
+			for polls.Load() <= int64(len(tt.script)) && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+			if err := <-errc; !errors.Is(err, context.Canceled) {
+				t.Fatalf("watch returned %v, want context.Canceled", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("callbacks = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("callbacks = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
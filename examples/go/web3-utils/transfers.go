@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// transferTopic is topic 0 of ERC-20 and ERC-721 Transfer events
+var transferTopic = EventTopic("Transfer(address,address,uint256)")
+
+// TokenTransfer is a decoded ERC-20 Transfer event
+type TokenTransfer struct {
+	From        common.Address
+	To          common.Address
+	Value       *big.Int
+	BlockNumber uint64
+	TxHash      common.Hash
+}
+
+// TokenTransfers returns the Transfer events of the ERC-20 token at
+// tokenAddress that credit recipient between blocks from and to, inclusive,
+// in chain order. A nil from starts at genesis and a nil to ends at the
+// latest block. The range is scanned in chunks (see WithLogChunkSize) since
+// providers cap the range of a single eth_getLogs call.
+func (w *Web3Utils) TokenTransfers(ctx context.Context, tokenAddress common.Address, from, to *big.Int, recipient common.Address) ([]TokenTransfer, error) {
+	var fromBlock, toBlock uint64
+	if from != nil {
+		fromBlock = from.Uint64()
+	}
+	if to != nil {
+		toBlock = to.Uint64()
+	} else {
+		head, err := w.GetBlockNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		toBlock = head
+	}
+	if fromBlock > toBlock {
+		return nil, fmt.Errorf("invalid block range %d-%d", fromBlock, toBlock)
+	}
+
+	logs, err := w.filterLogsChunked(ctx, transferQuery(tokenAddress, recipient), fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	transfers := make([]TokenTransfer, 0, len(logs))
+	for _, l := range logs {
+		transfer, err := decodeTransferLog(l)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers, nil
+}
+
+// transferQuery filters the token's Transfer events by the indexed
+// recipient, the third topic
+func transferQuery(token, recipient common.Address) ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		Addresses: []common.Address{token},
+		Topics:    [][]common.Hash{{transferTopic}, nil, {common.BytesToHash(recipient.Bytes())}},
+	}
+}
+
+// decodeTransferLog decodes an ERC-20 Transfer log: the parties are indexed
+// topics and the value is the log data
+func decodeTransferLog(l types.Log) (TokenTransfer, error) {
+	if len(l.Topics) != 3 || l.Topics[0] != transferTopic || len(l.Data) != 32 {
+		return TokenTransfer{}, fmt.Errorf("log %d of tx %s is not an ERC-20 Transfer", l.Index, l.TxHash.Hex())
+	}
+	return TokenTransfer{
+		From:        common.BytesToAddress(l.Topics[1].Bytes()),
+		To:          common.BytesToAddress(l.Topics[2].Bytes()),
+		Value:       new(big.Int).SetBytes(l.Data),
+		BlockNumber: l.BlockNumber,
+		TxHash:      l.TxHash,
+	}, nil
+}
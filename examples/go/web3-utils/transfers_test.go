@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestTokenTransfers(t *testing.T) {
+	token := common.HexToAddress(testTokenAddress)
+	recipient := common.HexToAddress(testAddress)
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	txHash := common.HexToHash(testTxHash)
+
+	type filter struct {
+		Address   []common.Address `json:"address"`
+		FromBlock hexutil.Uint64   `json:"fromBlock"`
+		ToBlock   hexutil.Uint64   `json:"toBlock"`
+		Topics    [][]common.Hash  `json:"topics"`
+	}
+	m := newMockRPC(t)
+	m.handle("eth_getLogs", func(params []json.RawMessage) (interface{}, error) {
+		var f filter
+		if err := json.Unmarshal(params[0], &f); err != nil {
+			return nil, err
+		}
+		// A 1.5 USDC transfer in block 15, inside the second chunk
+		if f.FromBlock > 15 || f.ToBlock < 15 {
+			return []types.Log{}, nil
+		}
+		return []types.Log{{
+			Address:     token,
+			Topics:      []common.Hash{transferTopic, common.BytesToHash(sender.Bytes()), common.BytesToHash(recipient.Bytes())},
+			Data:        common.LeftPadBytes(big.NewInt(1_500_000).Bytes(), 32),
+			BlockNumber: 15,
+			TxHash:      txHash,
+		}}, nil
+	})
+
+	transfers, err := m.dial(t, WithLogChunkSize(10)).TokenTransfers(context.Background(), token, big.NewInt(0), big.NewInt(25), recipient)
+	if err != nil {
+		t.Fatalf("TokenTransfers: %v", err)
+	}
+	if len(transfers) != 1 {
+		t.Fatalf("got %d transfers, want 1", len(transfers))
+	}
+	got := transfers[0]
+	if got.From != sender || got.To != recipient || got.Value.Int64() != 1_500_000 || got.BlockNumber != 15 || got.TxHash != txHash {
+		t.Fatalf("transfer = %+v", got)
+	}
+
+	calls := m.callParams("eth_getLogs")
+	ranges := [][2]uint64{{0, 9}, {10, 19}, {20, 25}}
+	if len(calls) != len(ranges) {
+		t.Fatalf("eth_getLogs called %d times, want %d", len(calls), len(ranges))
+	}
+	for i, params := range calls {
+		var f filter
+		if err := json.Unmarshal(params[0], &f); err != nil {
+			t.Fatal(err)
+		}
+		if uint64(f.FromBlock) != ranges[i][0] || uint64(f.ToBlock) != ranges[i][1] {
+			t.Fatalf("chunk %d = %d-%d, want %d-%d", i, f.FromBlock, f.ToBlock, ranges[i][0], ranges[i][1])
+		}
+		if len(f.Address) != 1 || f.Address[0] != token {
+			t.Fatalf("filter address = %v, want %s", f.Address, token.Hex())
+		}
+		if len(f.Topics) != 3 || len(f.Topics[0]) != 1 || f.Topics[0][0] != transferTopic ||
+			f.Topics[1] != nil || len(f.Topics[2]) != 1 || f.Topics[2][0] != common.BytesToHash(recipient.Bytes()) {
+			t.Fatalf("filter topics = %v", f.Topics)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ErrTxPoolUnsupported is returned when the endpoint does not serve the
+// txpool namespace
+var ErrTxPoolUnsupported = errors.New("txpool namespace not supported by this endpoint")
+
+// DefaultTxPoolLifetime is how long geth keeps non-executable transactions
+// in its pool before evicting them (txpool.lifetime)
+const DefaultTxPoolLifetime = 3 * time.Hour
+
+// latestHeader fetches the header of the latest block
+func (w *Web3Utils) latestHeader(ctx context.Context) (*types.Header, error) {
+	var header *types.Header
+	err := w.call(ctx, func(c *ethclient.Client) (err error) {
+		header, err = c.HeaderByNumber(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest header: %w", err)
+	}
+	return header, nil
+}
+
+// EstimateDropTime estimates how long a pending transaction priced below the
+// current network minimum will linger before nodes evict it. The minimum is
+// the latest base fee, or the suggested gas price on pre-London chains.
+// Competitively priced transactions return zero.
+//
+// This is a heuristic: eviction is modeled as linear in how far the fee cap
+// falls short of the minimum, scaled by DefaultTxPoolLifetime, because pools
+// under pressure evict the cheapest transactions first. Actual behavior
+// depends on each node's pool configuration and load.
+func (w *Web3Utils) EstimateDropTime(ctx context.Context, txHash string) (time.Duration, error) {
+	tx, isPending, err := w.GetTransactionByHash(ctx, txHash)
+	if err != nil {
+		return 0, err
+	}
+	if !isPending {
+		return 0, fmt.Errorf("transaction %s is not pending", txHash)
+	}
+
+	header, err := w.latestHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	floor := header.BaseFee
+	if floor == nil {
+		if floor, err = w.GetGasPrice(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	feeCap := tx.GasFeeCap()
+	if feeCap.Cmp(floor) >= 0 || floor.Sign() == 0 {
+		return 0, nil
+	}
+	ratio, _ := new(big.Float).Quo(new(big.Float).SetInt(feeCap), new(big.Float).SetInt(floor)).Float64()
+	return time.Duration(float64(DefaultTxPoolLifetime) * ratio), nil
+}
+
+// PendingCountFor returns how many pending transactions a sender has queued
+// in the node's pool. It uses txpool_contentFrom and, where the txpool
+// namespace is unavailable, falls back to the gap between the pending and
+// latest nonce.
+func (w *Web3Utils) PendingCountFor(ctx context.Context, address string) (uint64, error) {
+	account := common.HexToAddress(address)
+
+	var content struct {
+		Pending map[string]*types.Transaction `json:"pending"`
+	}
+	err := w.call(ctx, func(c *ethclient.Client) error {
+		return c.Client().CallContext(ctx, &content, "txpool_contentFrom", account)
+	})
+	if err == nil {
+		return uint64(len(content.Pending)), nil
+	}
+	if !isMethodNotFound(err) {
+		return 0, fmt.Errorf("failed to get txpool content: %w", err)
+	}
+
+	var pending, latest uint64
+	err = w.call(ctx, func(c *ethclient.Client) (err error) {
+		if pending, err = c.PendingNonceAt(ctx, account); err != nil {
+			return err
+		}
+		latest, err = c.NonceAt(ctx, account, nil)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get nonces: %w", err)
+	}
+	if pending < latest {
+		return 0, nil
+	}
+	return pending - latest, nil
+}
+
+// PendingTransactions returns the transactions sent by address that sit in
+// the node's pool, both pending (executable) and queued (waiting on a nonce
+// gap), ordered by nonce. It uses txpool_content and returns
+// ErrTxPoolUnsupported where the txpool namespace is unavailable.
+func (w *Web3Utils) PendingTransactions(ctx context.Context, address common.Address) ([]*types.Transaction, error) {
+	var content struct {
+		Pending map[common.Address]map[string]*types.Transaction `json:"pending"`
+		Queued  map[common.Address]map[string]*types.Transaction `json:"queued"`
+	}
+	err := w.call(ctx, func(c *ethclient.Client) error {
+		return c.Client().CallContext(ctx, &content, "txpool_content")
+	})
+	if isMethodNotFound(err) {
+		return nil, ErrTxPoolUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get txpool content: %w", err)
+	}
+
+	var txs []*types.Transaction
+	for _, tx := range content.Pending[address] {
+		txs = append(txs, tx)
+	}
+	for _, tx := range content.Queued[address] {
+		txs = append(txs, tx)
+	}
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce() < txs[j].Nonce() })
+	return txs, nil
+}
+
+// NonceGaps returns the nonces missing between address's confirmed nonce
+// and its highest pooled transaction. The lowest gap is the transaction
+// holding back everything queued after it; none means nothing is stuck on a
+// missing nonce.
+func (w *Web3Utils) NonceGaps(ctx context.Context, address common.Address) ([]uint64, error) {
+	txs, err := w.PendingTransactions(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	confirmed, err := w.NonceAt(ctx, address.Hex(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return nonceGaps(confirmed, txs), nil
+}
+
+// nonceGaps lists the nonces from next up to the highest nonce in txs that
+// no transaction uses. txs must be sorted by nonce.
+func nonceGaps(next uint64, txs []*types.Transaction) []uint64 {
+	var gaps []uint64
+	for _, tx := range txs {
+		for ; next < tx.Nonce(); next++ {
+			gaps = append(gaps, next)
+		}
+		if tx.Nonce() >= next {
+			next = tx.Nonce() + 1
+		}
+	}
+	return gaps
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestEstimateDropTime(t *testing.T) {
+	to := common.HexToAddress(testAddress)
+	tests := []struct {
+		name   string
+		feeCap int64
+		want   time.Duration
+	}{
+		{"underpriced", 10, 45 * time.Minute},
+		{"competitive", 50, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := dynamicTx(t, 0, to, gwei(1), gwei(tt.feeCap))
+			m := newMockRPC(t)
+			m.result("eth_getTransactionByHash", tx)
+			m.result("eth_getBlockByNumber", mockBlock(&types.Header{BaseFee: gwei(40)}))
+
+			d, err := m.dial(t).EstimateDropTime(context.Background(), tx.Hash().Hex())
+			if err != nil {
+				t.Fatalf("EstimateDropTime: %v", err)
+			}
+			if d != tt.want {
+				t.Fatalf("drop time = %v, want %v", d, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateDropTimeMinedTx(t *testing.T) {
+	tx := dynamicTx(t, 0, common.HexToAddress(testAddress), gwei(1), gwei(10))
+	raw := rpcTxJSON(t, tx, map[string]interface{}{"blockNumber": "0x10", "blockHash": common.Hash{0x1}})
+	m := newMockRPC(t)
+	m.result("eth_getTransactionByHash", raw)
+
+	if _, err := m.dial(t).EstimateDropTime(context.Background(), tx.Hash().Hex()); err == nil {
+		t.Fatal("expected error for mined transaction")
+	}
+}
+
+func TestPendingCountFor(t *testing.T) {
+	to := common.HexToAddress(testAddress)
+	m := newMockRPC(t)
+	m.result("txpool_contentFrom", map[string]interface{}{
+		"pending": map[string]interface{}{
+			"5": dynamicTx(t, 5, to, gwei(1), gwei(30)),
+			"6": dynamicTx(t, 6, to, gwei(1), gwei(30)),
+			"7": dynamicTx(t, 7, to, gwei(1), gwei(30)),
+		},
+		"queued": map[string]interface{}{
+			"9": dynamicTx(t, 9, to, gwei(1), gwei(30)),
+		},
+	})
+
+	n, err := m.dial(t).PendingCountFor(context.Background(), testAddress)
+	if err != nil {
+		t.Fatalf("PendingCountFor: %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("pending count = %d, want 3", n)
+	}
+}
+
+func TestPendingCountForNonceFallback(t *testing.T) {
+	m := newMockRPC(t)
+	mockNonces(m, 5, 8)
+
+	n, err := m.dial(t).PendingCountFor(context.Background(), testAddress)
+	if err != nil {
+		t.Fatalf("PendingCountFor: %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("pending count = %d, want 3", n)
+	}
+}
+
+func TestPendingTransactions(t *testing.T) {
+	to := testRouter
+	other := "0x1111111111111111111111111111111111111111"
+	// txpool_content groups transactions by sender, then by nonce
+	m := newMockRPC(t)
+	m.result("txpool_content", map[string]interface{}{
+		"pending": map[string]interface{}{
+			testAddress: map[string]interface{}{
+				"6": rpcTxJSON(t, dynamicTx(t, 6, to, gwei(1), gwei(30)), map[string]interface{}{"blockHash": nil, "blockNumber": nil}),
+				"5": rpcTxJSON(t, dynamicTx(t, 5, to, gwei(1), gwei(30)), map[string]interface{}{"blockHash": nil, "blockNumber": nil}),
+			},
+			other: map[string]interface{}{
+				"0": rpcTxJSON(t, dynamicTx(t, 0, to, gwei(1), gwei(30)), nil),
+			},
+		},
+		"queued": map[string]interface{}{
+			testAddress: map[string]interface{}{
+				"10": rpcTxJSON(t, dynamicTx(t, 10, to, gwei(1), gwei(30)), nil),
+				"8":  rpcTxJSON(t, dynamicTx(t, 8, to, gwei(1), gwei(30)), nil),
+			},
+		},
+	})
+	mockNonces(m, 5, 7)
+	w := m.dial(t)
+
+	txs, err := w.PendingTransactions(context.Background(), common.HexToAddress(testAddress))
+	if err != nil {
+		t.Fatalf("PendingTransactions: %v", err)
+	}
+	want := []uint64{5, 6, 8, 10}
+	if len(txs) != len(want) {
+		t.Fatalf("got %d transactions, want %d", len(txs), len(want))
+	}
+	for i, nonce := range want {
+		if txs[i].Nonce() != nonce {
+			t.Fatalf("tx %d nonce = %d, want %d", i, txs[i].Nonce(), nonce)
+		}
+	}
+
+	gaps, err := w.NonceGaps(context.Background(), common.HexToAddress(testAddress))
+	if err != nil {
+		t.Fatalf("NonceGaps: %v", err)
+	}
+	if len(gaps) != 2 || gaps[0] != 7 || gaps[1] != 9 {
+		t.Fatalf("nonce gaps = %v, want [7 9]", gaps)
+	}
+}
+
+func TestPendingTransactionsUnsupported(t *testing.T) {
+	_, err := newMockRPC(t).dial(t).PendingTransactions(context.Background(), common.HexToAddress(testAddress))
+	if !errors.Is(err, ErrTxPoolUnsupported) {
+		t.Fatalf("err = %v, want ErrTxPoolUnsupported", err)
+	}
+	if err.Error() != "txpool namespace not supported by this endpoint" {
+		t.Fatalf("err = %q", err)
+	}
+}
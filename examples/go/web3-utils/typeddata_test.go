@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// mailTypedData is the "Ether Mail" example from the EIP-712 specification
+func mailTypedData() apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Person": {
+				{Name: "name", Type: "string"},
+				{Name: "wallet", Type: "address"},
+			},
+			"Mail": {
+				{Name: "from", Type: "Person"},
+				{Name: "to", Type: "Person"},
+				{Name: "contents", Type: "string"},
+			},
+		},
+		PrimaryType: "Mail",
+		Domain:      mailDomain(),
+		Message: apitypes.TypedDataMessage{
+			"from": map[string]interface{}{
+				"name":   "Cow",
+				"wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826",
+			},
+			"to": map[string]interface{}{
+				"name":   "Bob",
+				"wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",
+			},
+			"contents": "Hello, Bob!",
+		},
+	}
+}
+
+func mailDomain() apitypes.TypedDataDomain {
+	return apitypes.TypedDataDomain{
+		Name:              "Ether Mail",
+		Version:           "1",
+		ChainId:           math.NewHexOrDecimal256(1),
+		VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+	}
+}
+
+// mailSignature is the specification's signature of mailTypedData by the
+// private key keccak256("cow")
+const mailSignature = "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+	"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562" + "1c"
+
+var mailSigner = common.HexToAddress("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+
+func TestTypedDataHash(t *testing.T) {
+	hash, err := TypedDataHash(mailTypedData())
+	if err != nil {
+		t.Fatalf("TypedDataHash: %v", err)
+	}
+	want := common.HexToHash("0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2")
+	if hash != want {
+		t.Fatalf("hash = %s, want %s", hash.Hex(), want.Hex())
+	}
+}
+
+func TestRecoverTypedDataSigner(t *testing.T) {
+	signer, err := RecoverTypedDataSigner(mailDomain(), mailTypedData(), hexutil.MustDecode(mailSignature))
+	if err != nil {
+		t.Fatalf("RecoverTypedDataSigner: %v", err)
+	}
+	if signer != mailSigner {
+		t.Fatalf("signer = %s, want %s", signer.Hex(), mailSigner.Hex())
+	}
+
+	otherChain := mailDomain()
+	otherChain.ChainId = math.NewHexOrDecimal256(10)
+	signer, err = RecoverTypedDataSigner(otherChain, mailTypedData(), hexutil.MustDecode(mailSignature))
+	if err == nil && signer == mailSigner {
+		t.Fatal("signature recovered to the same signer under a different domain")
+	}
+}
+
+func TestSignTypedDataMatchesSpec(t *testing.T) {
+	key, err := crypto.ToECDSA(crypto.Keccak256([]byte("cow")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := SignTypedData(mailTypedData(), key)
+	if err != nil {
+		t.Fatalf("SignTypedData: %v", err)
+	}
+	if !bytes.Equal(sig, hexutil.MustDecode(mailSignature)) {
+		t.Fatalf("signature = %x, want %s", sig, mailSignature)
+	}
+	if !VerifyTypedData(mailTypedData(), sig, mailSigner) {
+		t.Fatal("VerifyTypedData rejected the specification signature")
+	}
+}
+
+func TestSignTypedDataPermit(t *testing.T) {
+	key, err := crypto.HexToECDSA("0123456789012345678901234567890123456789012345678901234567890123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := common.HexToAddress("0x14791697260E4c9A71f18484C9f997B308e59325")
+	permit := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "USD Coin",
+			Version:           "2",
+			ChainId:           math.NewHexOrDecimal256(1),
+			VerifyingContract: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+		},
+		Message: apitypes.TypedDataMessage{
+			"owner":    owner.Hex(),
+			"spender":  "0x000000000022D473030F116dDEE9F6B43aC78BA3",
+			"value":    "1000000",
+			"nonce":    "0",
+			"deadline": "1700000000",
+		},
+	}
+
+	// eth_signTypedData_v4 of the permit with key
+	wantHash := common.HexToHash("0xe73844fb3da5bbc16aeabcb0a78756da5cfa7b8d1542d42b96b1df52e0e04250")
+	wantSig := hexutil.MustDecode("0x349aae569513f57fc085b9a63b62f00b398cc8e45b96a49afa1e253efdd3d18f40bc3d22e68a6624e5f5e1d69c47dfe70c442628e0a1272dc659958c91d6e7691c")
+
+	hash, err := TypedDataHash(permit)
+	if err != nil {
+		t.Fatalf("TypedDataHash: %v", err)
+	}
+	if hash != wantHash {
+		t.Fatalf("permit digest = %s, want %s", hash.Hex(), wantHash.Hex())
+	}
+	sig, err := SignTypedData(permit, key)
+	if err != nil {
+		t.Fatalf("SignTypedData: %v", err)
+	}
+	if !bytes.Equal(sig, wantSig) {
+		t.Fatalf("signature = %x, want %x", sig, wantSig)
+	}
+	if !VerifyTypedData(permit, sig, owner) {
+		t.Fatal("VerifyTypedData rejected a valid permit signature")
+	}
+
+	permit.Message["value"] = "2000000"
+	if VerifyTypedData(permit, sig, owner) {
+		t.Fatal("VerifyTypedData accepted a signature over a different value")
+	}
+}
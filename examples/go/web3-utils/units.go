@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// etherDecimals is the number of decimal places between Wei and ETH
+const etherDecimals = 18
+
+// gweiDecimals is the number of decimal places between Wei and Gwei
+const gweiDecimals = 9
+
+// FormatWei renders an integer amount of the smallest unit as an exact
+// decimal string with the given number of decimal places, e.g.
+// 1500000000000000000 with 18 decimals is "1.5". Trailing fractional zeros
+// are trimmed and no floating point is involved, unlike WeiToEth.
+func FormatWei(wei *big.Int, decimals int) string {
+	digits := new(big.Int).Abs(wei).String()
+	sign := ""
+	if wei.Sign() < 0 {
+		sign = "-"
+	}
+	if decimals <= 0 {
+		return sign + digits + strings.Repeat("0", -decimals)
+	}
+
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, frac := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if frac == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + frac
+}
+
+// FormatGwei renders a Wei amount in Gwei for display, e.g. "23.45 gwei",
+// rounded half away from zero to at most precision decimal places, with
+// trailing fractional zeros trimmed. A precision of 9 or more shows every
+// Wei exactly. No floating point is involved, so sub-gwei amounts keep their
+// digits.
+func FormatGwei(wei *big.Int, precision int) string {
+	if precision < 0 {
+		precision = 0
+	}
+	if precision > gweiDecimals {
+		precision = gweiDecimals
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(gweiDecimals-precision)), nil)
+	half := new(big.Int).Rsh(scale, 1)
+	rounded := new(big.Int).Abs(wei)
+	rounded.Add(rounded, half).Quo(rounded, scale)
+	if wei.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+	return FormatWei(rounded, precision) + " gwei"
+}
+
+// ParseEther parses a decimal ETH amount such as "1.5" into exact Wei. It
+// returns an error rather than rounding when the amount has more than 18
+// fractional digits.
+func ParseEther(s string) (*big.Int, error) {
+	text := strings.TrimSpace(s)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+
+	whole, frac, _ := strings.Cut(text, ".")
+	if whole == "" && frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return nil, fmt.Errorf("invalid ether amount %q", s)
+	}
+	if len(frac) > etherDecimals {
+		return nil, fmt.Errorf("ether amount %q has more than %d decimal places", s, etherDecimals)
+	}
+
+	wei, _ := new(big.Int).SetString("0"+whole+frac+strings.Repeat("0", etherDecimals-len(frac)), 10)
+	if negative {
+		wei.Neg(wei)
+	}
+	return wei, nil
+}
+
+// isDigits reports whether s consists of ASCII digits only
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
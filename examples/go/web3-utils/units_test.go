@@ -0,0 +1,100 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestFormatWei(t *testing.T) {
+	tests := []struct {
+		wei      string
+		decimals int
+		want     string
+	}{
+		{"1", 18, "0.000000000000000001"},
+		{"123456789123456789000000000", 18, "123456789.123456789"},
+		{"1000000000000000000", 18, "1"},
+		{"0", 18, "0"},
+		{"-1500000000000000000", 18, "-1.5"},
+		{"1500000", 6, "1.5"},
+		{"42", 0, "42"},
+	}
+	for _, tt := range tests {
+		wei, _ := new(big.Int).SetString(tt.wei, 10)
+		if got := FormatWei(wei, tt.decimals); got != tt.want {
+			t.Errorf("FormatWei(%s, %d) = %q, want %q", tt.wei, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestParseEther(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"0.000000000000000001", "1"},
+		{"123456789.123456789", "123456789123456789000000000"},
+		{"1", "1000000000000000000"},
+		{".5", "500000000000000000"},
+		{"-2.25", "-2250000000000000000"},
+	}
+	for _, tt := range tests {
+		got, err := ParseEther(tt.in)
+		if err != nil {
+			t.Fatalf("ParseEther(%q): %v", tt.in, err)
+		}
+		if got.String() != tt.want {
+			t.Fatalf("ParseEther(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	// Exact values survive a round trip
+	for _, s := range []string{"0.000000000000000001", "123456789.123456789"} {
+		wei, _ := ParseEther(s)
+		if back := FormatWei(wei, 18); back != s {
+			t.Fatalf("FormatWei(ParseEther(%q)) = %q", s, back)
+		}
+	}
+
+	for _, bad := range []string{"0.0000000000000000001", "", ".", "1.2.3", "1e18", "abc", "--1"} {
+		if _, err := ParseEther(bad); err == nil {
+			t.Errorf("ParseEther(%q) accepted invalid input", bad)
+		}
+	}
+}
+
+func TestGweiConversions(t *testing.T) {
+	if got := WeiToGwei(big.NewInt(23_450_000_000)).Text('f', 2); got != "23.45" {
+		t.Fatalf("WeiToGwei = %s, want 23.45", got)
+	}
+	if got := WeiToGwei(big.NewInt(1)).Text('g', 10); got != "1e-09" {
+		t.Fatalf("WeiToGwei(1 wei) = %s, want 1e-09", got)
+	}
+	if got := GweiToWei(big.NewFloat(1.5)); got.Cmp(big.NewInt(1_500_000_000)) != 0 {
+		t.Fatalf("GweiToWei(1.5) = %s", got)
+	}
+}
+
+func TestFormatGwei(t *testing.T) {
+	tests := []struct {
+		wei       int64
+		precision int
+		want      string
+	}{
+		{0, 2, "0 gwei"},
+		{23_450_000_000, 2, "23.45 gwei"},
+		{23_455_000_000, 2, "23.46 gwei"},
+		{23_454_999_999, 2, "23.45 gwei"},
+		{-23_455_000_000, 2, "-23.46 gwei"},
+		{20_000_000_000, 2, "20 gwei"},
+		{1_999_999_999, 0, "2 gwei"},
+		{1, 9, "0.000000001 gwei"},
+		{123_456_789, 12, "0.123456789 gwei"},
+		{1, 2, "0 gwei"},
+	}
+	for _, tt := range tests {
+		if got := FormatGwei(big.NewInt(tt.wei), tt.precision); got != tt.want {
+			t.Errorf("FormatGwei(%d, %d) = %q, want %q", tt.wei, tt.precision, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// DefaultPollInterval is how often watchers poll the node for new blocks
+	DefaultPollInterval = 12 * time.Second
+	// DefaultConfirmationTarget is the confirmation count at which
+	// ConfirmationStream stops
+	DefaultConfirmationTarget = 12
+)
+
+// watchBlocks polls the latest block number and emits it every time it
+// advances. The channel is closed when ctx is cancelled or on Shutdown.
+func (w *Web3Utils) watchBlocks(ctx context.Context) <-chan uint64 {
+	out := make(chan uint64)
+	ctx, done, err := w.watch(ctx)
+	if err != nil {
+		close(out)
+		return out
+	}
+	go func() {
+		defer done()
+		defer close(out)
+
+		ticker := time.NewTicker(w.currentSettings(ctx).pollInterval)
+		defer ticker.Stop()
+
+		var last uint64
+		for {
+			if head, err := w.GetBlockNumber(ctx); err == nil && head > last {
+				last = head
+				select {
+				case out <- head:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return out
+}
+
+// ConfirmationStream emits the confirmation count of a transaction each time
+// a new block arrives, starting once the transaction is mined. The channel is
+// closed after the configured confirmation target is reached, when ctx is
+// cancelled or on Shutdown. Lookup failures are treated as transient and
+// retried on the next block.
+func (w *Web3Utils) ConfirmationStream(ctx context.Context, txHash string) <-chan uint64 {
+	hash := common.HexToHash(txHash)
+	out := make(chan uint64)
+	ctx, done, err := w.watch(ctx)
+	if err != nil {
+		close(out)
+		return out
+	}
+	go func() {
+		defer done()
+		defer close(out)
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		target := w.currentSettings(ctx).confirmationTarget
+		var last uint64
+		for head := range w.watchBlocks(ctx) {
+			var receipt *types.Receipt
+			err := w.call(ctx, func(c *ethclient.Client) (err error) {
+				receipt, err = c.TransactionReceipt(ctx, hash)
+				return err
+			})
+			if err != nil || receipt.BlockNumber == nil || head < receipt.BlockNumber.Uint64() {
+				continue
+			}
+
+			confirmations := head - receipt.BlockNumber.Uint64() + 1
+			if confirmations == last {
+				continue
+			}
+			last = confirmations
+			select {
+			case out <- confirmations:
+			case <-ctx.Done():
+				return
+			}
+			if confirmations >= target {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// BalanceBatchError reports the elements of a BalanceOfMany batch that
+// failed, keyed by their index in the addresses slice
+type BalanceBatchError struct {
+	Errors map[int]error
+}
+
+// Indices returns the failed indices in ascending order
+func (e *BalanceBatchError) Indices() []int {
+	indices := make([]int, 0, len(e.Errors))
+	for i := range e.Errors {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+func (e *BalanceBatchError) Error() string {
+	indices := e.Indices()
+	msgs := make([]string, len(indices))
+	for i, index := range indices {
+		msgs[i] = fmt.Sprintf("index %d: %v", index, e.Errors[index])
+	}
+	return fmt.Sprintf("failed to get %d balances: %s", len(indices), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the per-element errors in index order
+func (e *BalanceBatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, i := range e.Indices() {
+		errs = append(errs, e.Errors[i])
+	}
+	return errs
+}
+
+// BalanceOfMany reads the latest balances of addresses in a single JSON-RPC
+// batch request. Balances are returned in the same order as addresses. If
+// some elements of the batch fail, their entries are nil and the returned
+// error is a *BalanceBatchError listing them; the other balances are still
+// returned.
+func (w *Web3Utils) BalanceOfMany(ctx context.Context, addresses []common.Address) ([]*big.Int, error) {
+	results := make([]hexutil.Big, len(addresses))
+	batch := make([]rpc.BatchElem, len(addresses))
+	for i, address := range addresses {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getBalance",
+			Args:   []interface{}{address, "latest"},
+			Result: &results[i],
+		}
+	}
+	err := w.call(ctx, func(c *ethclient.Client) error {
+		return c.Client().BatchCallContext(ctx, batch)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balances: %w", err)
+	}
+
+	balances := make([]*big.Int, len(addresses))
+	failed := make(map[int]error)
+	for i, elem := range batch {
+		if elem.Error != nil {
+			failed[i] = fmt.Errorf("failed to get balance of %s: %w", addresses[i].Hex(), elem.Error)
+			continue
+		}
+		balances[i] = results[i].ToInt()
+	}
+	if len(failed) > 0 {
+		return balances, &BalanceBatchError{Errors: failed}
+	}
+	return balances, nil
+}
+
+// batchBalances reads the latest balances of hex addresses with
+// BalanceOfMany, failing if any of them could not be read
+func (w *Web3Utils) batchBalances(ctx context.Context, addresses []string) ([]*big.Int, error) {
+	accounts := make([]common.Address, len(addresses))
+	for i, address := range addresses {
+		accounts[i] = common.HexToAddress(address)
+	}
+	balances, err := w.BalanceOfMany(ctx, accounts)
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// WatchBalances polls the balances of addresses every interval, reading all
+// of them in one batch request, and calls cb for each address whose balance
+// changed since the previous poll. The first poll only records the starting
+// balances. Failed polls are skipped. It blocks until ctx is cancelled and
+// then returns ctx.Err(), or ErrShutdown if stopped by Shutdown.
+func (w *Web3Utils) WatchBalances(ctx context.Context, addresses []string, interval time.Duration, cb func(address string, old, new *big.Int)) error {
+	parent := ctx
+	ctx, done, err := w.watch(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last []*big.Int
+	for {
+		if balances, err := w.batchBalances(ctx, addresses); err == nil {
+			if last != nil {
+				for i, balance := range balances {
+					if balance.Cmp(last[i]) != 0 {
+						cb(addresses[i], last[i], balance)
+					}
+				}
+			}
+			last = balances
+		}
+
+		select {
+		case <-ctx.Done():
+			if parent.Err() == nil {
+				return ErrShutdown
+			}
+			return parent.Err()
+		case <-ticker.C:
+		}
+	}
+}
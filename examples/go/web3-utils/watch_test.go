@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// advancingHead registers an eth_blockNumber handler that moves the chain
+// forward by one block on every call, starting at start
+func advancingHead(m *mockRPC, start uint64) {
+	var head atomic.Uint64
+	head.Store(start - 1)
+	m.handle("eth_blockNumber", func([]json.RawMessage) (interface{}, error) {
+		return hexutil.Uint64(head.Add(1)), nil
+	})
+}
+
+func TestConfirmationStream(t *testing.T) {
+	m := newMockRPC(t)
+	advancingHead(m, 100)
+	m.result("eth_getTransactionReceipt", mockReceipt(testTxHash, 100, types.ReceiptStatusSuccessful))
+
+	w := m.dial(t, WithPollInterval(5*time.Millisecond), WithConfirmationTarget(4))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var got []uint64
+	for n := range w.ConfirmationStream(ctx, testTxHash) {
+		got = append(got, n)
+	}
+	if len(got) == 0 || got[len(got)-1] != 4 {
+		t.Fatalf("confirmations = %v, want stream ending at 4", got)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i] <= got[i-1] {
+			t.Fatalf("confirmations not increasing: %v", got)
+		}
+	}
+}
+
+func TestConfirmationStreamCancel(t *testing.T) {
+	m := newMockRPC(t)
+	advancingHead(m, 100)
+
+	w := m.dial(t, WithPollInterval(5*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := w.ConfirmationStream(ctx, testTxHash)
+	cancel()
+
+	select {
+	case _, ok := <-stream:
+		if ok {
+			t.Fatal("unexpected confirmation for unknown tx")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream not closed after cancel")
+	}
+}
+
+func TestWatchBalances(t *testing.T) {
+	static := "0x1111111111111111111111111111111111111111"
+	moving := "0x2222222222222222222222222222222222222222"
+
+	var polls atomic.Int64
+	m := newMockRPC(t)
+	m.handle("eth_getBalance", func(params []json.RawMessage) (interface{}, error) {
+		var addr common.Address
+		if err := json.Unmarshal(params[0], &addr); err != nil {
+			return nil, err
+		}
+		if addr == common.HexToAddress(moving) {
+			// Every poll after the first sees one more wei
+			return hexutil.Big(*big.NewInt(100 + polls.Add(1) - 1)), nil
+		}
+		return hexutil.Big(*big.NewInt(500)), nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	type change struct {
+		address  string
+		old, new int64
+	}
+	changes := make(chan change, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- m.dial(t).WatchBalances(ctx, []string{static, moving}, 5*time.Millisecond, func(address string, old, new *big.Int) {
+			select {
+			case changes <- change{address, old.Int64(), new.Int64()}:
+			default:
+			}
+		})
+	}()
+
+	for i := int64(0); i < 2; i++ {
+		select {
+		case c := <-changes:
+			if c.address != moving {
+				t.Fatalf("callback for unchanged address %s", c.address)
+			}
+			if c.old != 100+i || c.new != 101+i {
+				t.Fatalf("change = %d -> %d, want %d -> %d", c.old, c.new, 100+i, 101+i)
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for balance change")
+		}
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("WatchBalances returned %v, want context.Canceled", err)
+	}
+	if len(m.batchSizes()) == 0 {
+		t.Fatal("balances were not read in batches")
+	}
+	for _, size := range m.batchSizes() {
+		if size != 2 {
+			t.Fatalf("batch sizes = %v, want batches of 2", m.batchSizes())
+		}
+	}
+}
+
+func TestBalanceOfMany(t *testing.T) {
+	// Each address holds its last byte in wei; 0x..02 and 0x..04 fail
+	m := newMockRPC(t)
+	m.handle("eth_getBalance", func(params []json.RawMessage) (interface{}, error) {
+		var addr common.Address
+		if err := json.Unmarshal(params[0], &addr); err != nil {
+			return nil, err
+		}
+		if last := addr[common.AddressLength-1]; last%2 == 0 {
+			return nil, &rpcError{code: -32000, msg: "header not found"}
+		}
+		return hexutil.Big(*big.NewInt(int64(addr[common.AddressLength-1]))), nil
+	})
+	w := m.dial(t)
+
+	odd := []common.Address{common.BigToAddress(big.NewInt(5)), common.BigToAddress(big.NewInt(1)), common.BigToAddress(big.NewInt(3))}
+	balances, err := w.BalanceOfMany(context.Background(), odd)
+	if err != nil {
+		t.Fatalf("BalanceOfMany: %v", err)
+	}
+	for i, want := range []int64{5, 1, 3} {
+		if balances[i].Int64() != want {
+			t.Fatalf("balance[%d] = %s, want %d", i, balances[i], want)
+		}
+	}
+
+	mixed := []common.Address{
+		common.BigToAddress(big.NewInt(1)), common.BigToAddress(big.NewInt(2)),
+		common.BigToAddress(big.NewInt(3)), common.BigToAddress(big.NewInt(4)),
+	}
+	balances, err = w.BalanceOfMany(context.Background(), mixed)
+	var batchErr *BalanceBatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("err = %v, want *BalanceBatchError", err)
+	}
+	if got := batchErr.Indices(); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Fatalf("failed indices = %v, want [1 3]", got)
+	}
+	if len(balances) != len(mixed) {
+		t.Fatalf("got %d balances, want %d", len(balances), len(mixed))
+	}
+	if balances[1] != nil || balances[3] != nil {
+		t.Fatalf("failed entries = %v, %v, want nil", balances[1], balances[3])
+	}
+	if balances[0].Int64() != 1 || balances[2].Int64() != 3 {
+		t.Fatalf("balances = %v, want 1 and 3 at indices 0 and 2", balances)
+	}
+	if sizes := m.batchSizes(); len(sizes) != 2 || sizes[1] != len(mixed) {
+		t.Fatalf("batch sizes = %v, want one batch per call", sizes)
+	}
+}